@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/audit"
+	"github.com/urfave/cli/v2"
+)
+
+func auditCommand() *cli.Command {
+	defaults := audit.DefaultConfig()
+
+	return &cli.Command{
+		Name:      "audit",
+		Usage:     "walk a directory and report risky modes with the chmod command that fixes them, exits with 1 when issues are found",
+		ArgsUsage: "[dir]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   "text",
+				Usage:   "report `FORMAT`, text or json",
+			},
+			&cli.StringSliceFlag{
+				Name:  "enable",
+				Usage: "only run the given `RULE`s (default: all rules)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "disable",
+				Usage: "skip the given `RULE`s",
+			},
+			&cli.StringSliceFlag{
+				Name:  "key-pattern",
+				Usage: "file names matching `PATTERN` are treated as private keys, in addition to " + strings.Join(defaults.KeyPatterns, ", "),
+			},
+			&cli.StringSliceFlag{
+				Name:  "allow-setuid",
+				Usage: "file names matching `PATTERN` are expected to be setuid/setgid",
+			},
+			&cli.StringSliceFlag{
+				Name:  "exclude",
+				Value: cli.NewStringSlice(defaults.Exclude...),
+				Usage: "skip entries matching `PATTERN`, excluded directories aren't descended into, replaces the default so .git can be audited",
+			},
+			&cli.BoolFlag{
+				Name:  "list-rules",
+				Usage: "list the available rules and exit",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// key patterns given extend the defaults, exclusions replace them
+			config := audit.Config{
+				Enable:      c.StringSlice("enable"),
+				Disable:     c.StringSlice("disable"),
+				KeyPatterns: append(defaults.KeyPatterns, c.StringSlice("key-pattern")...),
+				AllowSetuid: c.StringSlice("allow-setuid"),
+				Exclude:     c.StringSlice("exclude"),
+			}
+
+			w := c.App.Writer
+
+			if c.Bool("list-rules") {
+				for _, r := range audit.Rules(config) {
+					fmt.Fprintf(w, "%-7s %s\n", r.Severity, r.Name)
+				}
+
+				return nil
+			}
+
+			format := c.String("format")
+
+			if format != "text" && format != "json" {
+				return fmt.Errorf("unknown format '%s', expected text or json", format)
+			}
+
+			if c.NArg() > 1 {
+				return errors.New("audit expects at most one directory argument")
+			}
+
+			root := "."
+
+			if c.NArg() == 1 {
+				root = c.Args().First()
+			}
+
+			report, err := audit.Audit(root, config)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				enc := json.NewEncoder(w)
+				enc.SetIndent("", "  ")
+
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				writeReport(w, c.App.ErrWriter, report)
+			}
+
+			if len(report.Findings) > 0 {
+				return cli.Exit(fmt.Sprintf("%d issue(s) found in %d path(s) scanned", len(report.Findings), report.Scanned), 1)
+			}
+
+			return nil
+		},
+	}
+}
+
+func writeReport(w, errW io.Writer, report *audit.Report) {
+	for _, e := range report.Errors {
+		fmt.Fprintf(errW, "skipped %s: %s\n", e.Path, e.Error)
+	}
+
+	if len(report.Findings) == 0 {
+		fmt.Fprintf(w, "no issues found in %d path(s) scanned\n", report.Scanned)
+		return
+	}
+
+	for i, f := range report.Findings {
+		// findings for the same path are adjacent, print the path once
+		if i == 0 || report.Findings[i-1].Path != f.Path {
+			fmt.Fprintf(w, "%s (%s)\n", f.Path, f.Mode)
+		}
+
+		fmt.Fprintf(w, "  %-7s %-32s %s\n", f.Severity, f.Rule, f.Message)
+		fmt.Fprintf(w, "  %-7s fix: %s\n", "", f.Fix)
+	}
+}
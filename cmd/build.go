@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/urfave/cli/v2"
+)
+
+// buildClasses maps the class flags to the user they set and the class symbol
+// used for relative clauses
+var buildClasses = []struct {
+	flag   string
+	user   generate.User
+	symbol string
+}{
+	{"owner", generate.Owner, "u"},
+	{"group", generate.Group, "g"},
+	{"other", generate.Other, "o"},
+}
+
+// specialSymbols maps the names accepted by --special to their relative clause
+var specialSymbols = map[string]struct {
+	special generate.Special
+	clause  string
+}{
+	"setuid": {generate.SetUID, "u%ss"},
+	"setgid": {generate.SetGID, "g%ss"},
+	"sticky": {generate.Sticky, "%st"},
+}
+
+// optionFlags are the chmod options that can be passed through, at most one applies
+var optionFlags = []string{"verbose", "changes", "silent"}
+
+// buildFlags build the command without the tui when any of them is set
+func buildFlags() []cli.Flag {
+	flags := []cli.Flag{}
+
+	for _, class := range buildClasses {
+		flags = append(flags, &cli.StringFlag{
+			Name:  class.flag,
+			Usage: fmt.Sprintf("print the command with `PERMS` (e.g rwx, rx or none) for the %s, prefixed with +, - or = in relative mode", class.flag),
+		})
+	}
+
+	flags = append(flags, &cli.StringFlag{
+		Name:  "special",
+		Usage: "print the command with the comma separated special `BITS` (setuid, setgid, sticky), each prefixed with + or - in relative mode",
+	})
+
+	for _, option := range optionFlags {
+		flags = append(flags, &cli.BoolFlag{
+			Name:  option,
+			Usage: fmt.Sprintf("print the command with chmod's --%s option", option),
+		})
+	}
+
+	return flags
+}
+
+// buildRequested reports whether any of the build flags is set
+func buildRequested(c *cli.Context) bool {
+	for _, f := range buildFlags() {
+		if c.IsSet(f.Names()[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildCommand returns the chmod command for the build flags in the given
+// command mode, followed by the shell-quoted targets
+func buildCommand(c *cli.Context, mode string, targets []string) (string, error) {
+	var (
+		state *generate.State
+		err   error
+	)
+
+	switch strings.ToLower(mode) {
+	case "", "symbolic":
+		mode = "Symbolic"
+
+	case "octal":
+		mode = "Octal"
+
+	case "relative":
+		mode = "Relative"
+
+	default:
+		return "", fmt.Errorf("invalid command mode '%s': expected one of octal, symbolic, relative", mode)
+	}
+
+	if mode == "Relative" {
+		state, err = buildRelativeState(c)
+	} else {
+		state, err = buildAbsoluteState(c)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	built, err := state.ModeArgument(mode)
+	if err != nil {
+		return "", err
+	}
+
+	option := ""
+
+	for _, v := range optionFlags {
+		if !c.Bool(v) {
+			continue
+		}
+
+		if option != "" {
+			return "", fmt.Errorf("--%s and --%s can't be used together", option, v)
+		}
+
+		option = v
+	}
+
+	command := []string{"chmod"}
+
+	if option != "" {
+		command = append(command, "--"+option)
+	}
+
+	command = append(command, built)
+
+	for _, v := range targets {
+		command = append(command, common.QuotePath(v))
+	}
+
+	return strings.Join(command, " "), nil
+}
+
+func buildAbsoluteState(c *cli.Context) (*generate.State, error) {
+	state := generate.NewState()
+
+	for _, class := range buildClasses {
+		perms := c.String(class.flag)
+
+		if perms == "none" || perms == "-" {
+			continue
+		}
+
+		for _, p := range perms {
+			if !strings.ContainsRune("rwxX", p) {
+				return nil, fmt.Errorf("invalid --%s '%s': expected a combination of r, w, x and X, or none", class.flag, perms)
+			}
+
+			state.Users[class.user][generate.Access(string(p))] = true
+		}
+	}
+
+	for _, name := range specialNamesFlag(c) {
+		special, ok := specialSymbols[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid --special '%s': expected setuid, setgid or sticky", name)
+		}
+
+		state.Special[special.special] = true
+	}
+
+	return state, nil
+}
+
+func buildRelativeState(c *cli.Context) (*generate.State, error) {
+	clauses := []string{}
+
+	for _, class := range buildClasses {
+		perms := c.String(class.flag)
+
+		if perms == "" {
+			continue
+		}
+
+		if !strings.ContainsAny(perms[:1], "+-=") {
+			return nil, fmt.Errorf("invalid --%s '%s': relative permissions start with +, - or =, e.g +x", class.flag, perms)
+		}
+
+		clauses = append(clauses, class.symbol+perms)
+	}
+
+	for _, name := range specialNamesFlag(c) {
+		special, ok := specialSymbols[strings.TrimLeft(name, "+-")]
+		if !ok || !strings.ContainsAny(name[:1], "+-") {
+			return nil, fmt.Errorf("invalid --special '%s': expected setuid, setgid or sticky prefixed with + or -", name)
+		}
+
+		clauses = append(clauses, fmt.Sprintf(special.clause, name[:1]))
+	}
+
+	if len(clauses) == 0 {
+		return nil, errors.New("relative mode needs at least one change, e.g --group +w")
+	}
+
+	return generate.ParseRelative(strings.Join(clauses, ","))
+}
+
+// specialNamesFlag splits the --special flag into lower case names
+func specialNamesFlag(c *cli.Context) []string {
+	names := []string{}
+
+	for _, name := range strings.Split(c.String("special"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name != "" && name != "none" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
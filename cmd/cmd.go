@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/config"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/history"
 	"github.com/Mayowa-Ojo/chmod-cli/internal/ui"
 	"github.com/urfave/cli/v2"
 )
@@ -8,18 +13,178 @@ import (
 // Execute serves as the cli entry point
 func Execute() *cli.App {
 	app := &cli.App{
-		Name:     "chmod-cli",
-		Usage:    "generate file permissions with the bat of an eye",
-		Commands: []*cli.Command{},
+		Name:      "chmod-cli",
+		Usage:     "generate file permissions with the bat of an eye",
+		ArgsUsage: "[path...]",
+		Commands: []*cli.Command{
+			convertCommand(),
+			explainCommand(),
+			umaskCommand(),
+			lintCommand(),
+			auditCommand(),
+			presetCommand(),
+			initCommand(),
+			historyCommand(),
+		},
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "prefill the permissions from the mode of `PATH` (defaults to the first target, then the working directory)",
+			},
+			&cli.StringFlag{
+				Name:    "config",
+				EnvVars: []string{"CHMOD_CLI_CONFIG"},
+				Usage:   "read the defaults from `FILE` instead of config.yaml in the user config directory",
+			},
+			&cli.StringFlag{
+				Name:  "option",
+				Usage: "start with the `OPTION` flag selected: verbose, changes, silent or default",
+			},
+			&cli.StringFlag{
+				Name:  "mode",
+				Usage: "start in command `MODE`: octal, symbolic or relative",
+			},
+			&cli.StringFlag{
+				Name:  "path-type",
+				Usage: "start with the `TYPE` path type: file or directory",
+			},
+			&cli.StringFlag{
+				Name:  "preset",
+				Usage: "start with the permissions of the preset named `NAME`",
+			},
+			&cli.StringFlag{
+				Name:  "theme",
+				Usage: "use the `THEME` colors: default, ocean or mono",
+			},
+			&cli.BoolFlag{
+				Name:  "print",
+				Usage: "draw the tui on stderr and print the command to stdout on quit, e.g eval \"$(chmod-cli --print)\"",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "write the command to `FILE` on quit instead of printing it, used by the shell integration",
+			},
+		}, buildFlags()...),
 		Action: func(c *cli.Context) error {
+			targets := c.Args().Slice()
+			source := c.String("from")
+
+			if source == "" && len(targets) > 0 {
+				source = targets[0]
+			}
+
+			// scripts get the same output regardless of the user's config file
+			if buildRequested(c) {
+				command, err := buildCommand(c, c.String("mode"), targets)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(c.App.Writer, command)
+
+				return nil
+			}
+
+			conf, err := loadConfig(c)
+			if err != nil {
+				return err
+			}
+
+			sources, err := config.LoadPresetSources()
+			if err != nil {
+				return err
+			}
+
+			// the history is best effort, without a data directory nothing is recorded
+			historyPath, _ := history.UserHistoryPath()
+
+			settings := ui.Settings{
+				Targets:     targets,
+				Source:      source,
+				Presets:     config.MergePresets(sources),
+				Option:      conf.Option,
+				CommandMode: conf.Mode,
+				PathType:    conf.PathType,
+				Preset:      conf.Preset,
+				Theme:       conf.Theme,
+				Print:       c.Bool("print") || c.String("output") != "",
+				Input:       c.App.Reader,
+				Output:      c.App.Writer,
+				HistoryPath: historyPath,
+			}
+
+			if c.Bool("print") {
+				settings.Output = c.App.ErrWriter
+			}
 
-			if err := ui.InitScreen(); err != nil {
+			// an explicit source is more specific than the configured preset
+			if source != "" && !c.IsSet("preset") {
+				settings.Preset = ""
+			}
+
+			command, err := ui.InitScreen(settings)
+			if err != nil {
 				return err
 			}
 
+			if !settings.Print {
+				return nil
+			}
+
+			// exit with an error when cancelled so callers can tell it apart
+			if command == "" {
+				return cli.Exit("", 1)
+			}
+
+			if output := c.String("output"); output != "" {
+				return os.WriteFile(output, []byte(command+"\n"), 0600)
+			}
+
+			fmt.Fprintln(c.App.Writer, command)
+
 			return nil
 		},
 	}
 
 	return app
 }
+
+// loadConfig reads the config file and applies the flags set on the command
+// line over it
+func loadConfig(c *cli.Context) (*config.Config, error) {
+	path := c.String("config")
+
+	if path == "" {
+		userPath, err := config.UserConfigPath()
+		if err != nil {
+			return nil, err
+		}
+
+		path = userPath
+	} else if _, err := os.Stat(path); err != nil {
+		// only the default config file is optional
+		return nil, err
+	}
+
+	conf, err := config.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range []struct {
+		flag  string
+		value *string
+	}{
+		{"option", &conf.Option},
+		{"mode", &conf.Mode},
+		{"path-type", &conf.PathType},
+		{"preset", &conf.Preset},
+		{"theme", &conf.Theme},
+	} {
+		if c.IsSet(v.flag) {
+			*v.value = c.String(v.flag)
+		}
+	}
+
+	return conf, nil
+}
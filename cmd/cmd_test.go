@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+// isolate points the user config and data directories at a temporary
+// directory so the tests don't read or write the real ones
+func isolate(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, v := range []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME"} {
+		old, ok := os.LookupEnv(v)
+
+		os.Setenv(v, filepath.Join(dir, strings.ToLower(v)))
+
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(v, old)
+			} else {
+				os.Unsetenv(v)
+			}
+		})
+	}
+}
+
+// pipeKeys returns a reader the tui reads keys from. It's a pipe rather than a
+// strings.Reader, bubbletea cancels reading from files without racing
+func pipeKeys(t *testing.T, keys string) *os.File {
+	is := require.New(t)
+
+	r, w, err := os.Pipe()
+	is.NoError(err)
+
+	t.Cleanup(func() { r.Close() })
+
+	_, err = w.WriteString(keys)
+	is.NoError(err)
+	is.NoError(w.Close())
+
+	return r
+}
+
+func TestOutput(t *testing.T) {
+	is := require.New(t)
+	isolate(t)
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "my deploy.sh")
+	output := filepath.Join(dir, "command")
+
+	is.NoError(os.WriteFile(target, []byte{}, 0755))
+
+	app := Execute()
+	app.Reader = pipeKeys(t, "q")
+	app.Writer = &bytes.Buffer{}
+	app.ErrWriter = &bytes.Buffer{}
+
+	is.NoError(app.Run([]string{"chmod-cli", "--mode", "symbolic", "--preset", "Regular file", "--output", output, target}))
+
+	data, err := os.ReadFile(output)
+	is.NoError(err)
+
+	expected := "chmod u=rw,go=r " + common.ShellQuote(target) + "\n"
+
+	if command := string(data); command != expected {
+		t.Errorf("Expected command to be '%s', instead got '%s'", expected, command)
+	}
+
+	// the mode is an expression chmod accepts
+	_, err = generate.ParseRelative(strings.Fields(string(data))[1])
+	is.NoError(err)
+}
+
+func TestBuildFlags(t *testing.T) {
+	is := require.New(t)
+	isolate(t)
+
+	commands := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--owner", "rwx", "--group", "rx", "--other", "none", "--mode", "octal", "--verbose"}, "chmod --verbose 750"},
+		{[]string{"--owner", "rwX", "--group", "rX", "--other", "none", "./deploy"}, "chmod u=rwX,g=rX,o= ./deploy"},
+		{[]string{"--mode", "symbolic", "--owner", "rw", "--special", "sticky"}, "chmod u=rw,go=,+t"},
+		{[]string{"--owner", "rwx", "--group", "rwx", "--other", "rx", "--special", "setgid", "--mode", "octal"}, "chmod 2775"},
+		{[]string{"--owner", "rw", "--mode", "OCTAL", "--changes"}, "chmod --changes 600"},
+		{[]string{"--mode", "relative", "--group", "+w", "--other", "-rwx", "--special", "+setgid"}, "chmod g+ws,o-rwx"},
+		{[]string{"--mode", "relative", "--special", "+sticky,-setuid"}, "chmod u-s,+t"},
+		{[]string{"--mode", "octal", "--owner", "rw", "my file", "it's"}, `chmod 600 'my file' 'it'\''s'`},
+		{[]string{"--mode", "octal", "--owner", "rw", "--", "-rf"}, "chmod 600 ./-rf"},
+	}
+
+	for _, v := range commands {
+		out := &bytes.Buffer{}
+
+		app := Execute()
+		app.Writer = out
+
+		is.NoError(app.Run(append([]string{"chmod-cli"}, v.args...)), v.args)
+
+		if command := strings.TrimSpace(out.String()); command != v.expected {
+			t.Errorf("Expected %v to print '%s', instead got '%s'", v.args, v.expected, command)
+		}
+	}
+
+	invalid := [][]string{
+		{"--verbose", "--silent"},
+		{"--owner", "rwz"},
+		{"--mode", "hex", "--owner", "r"},
+		{"--mode", "relative", "--group", "w"},
+		{"--mode", "relative", "--special", "setgid"},
+		{"--mode", "relative", "--verbose"},
+		{"--special", "bogus"},
+	}
+
+	for _, args := range invalid {
+		out := &bytes.Buffer{}
+
+		app := Execute()
+		app.Writer = out
+
+		is.Error(app.Run(append([]string{"chmod-cli"}, args...)), args)
+		is.Empty(out.String(), args)
+	}
+}
+
+func TestAuditDefaults(t *testing.T) {
+	is := require.New(t)
+	isolate(t)
+
+	dir := t.TempDir()
+
+	for _, v := range []string{".git", "node_modules"} {
+		is.NoError(os.Mkdir(filepath.Join(dir, v), 0755))
+		is.NoError(os.WriteFile(filepath.Join(dir, v, "id_rsa"), []byte{}, 0644))
+	}
+
+	is.NoError(os.WriteFile(filepath.Join(dir, "id_rsa"), []byte{}, 0644))
+	is.NoError(os.WriteFile(filepath.Join(dir, "tls.pem"), []byte{}, 0644))
+
+	out := &bytes.Buffer{}
+
+	app := Execute()
+	app.Writer = out
+	app.ErrWriter = &bytes.Buffer{}
+	app.ExitErrHandler = func(c *cli.Context, err error) {}
+
+	is.Error(app.Run([]string{"chmod-cli", "audit", "--key-pattern", "*.pem", dir}))
+
+	// key patterns add to the defaults, .git is excluded by default
+	report := out.String()
+
+	is.Contains(report, filepath.Join(dir, "id_rsa"))
+	is.Contains(report, filepath.Join(dir, "tls.pem"))
+	is.Contains(report, filepath.Join(dir, "node_modules", "id_rsa"))
+	is.NotContains(report, ".git")
+
+	out.Reset()
+
+	app = Execute()
+	app.Writer = out
+	app.ErrWriter = &bytes.Buffer{}
+	app.ExitErrHandler = func(c *cli.Context, err error) {}
+
+	// exclusions replace the default
+	is.Error(app.Run([]string{"chmod-cli", "audit", "--exclude", "node_modules", dir}))
+
+	report = out.String()
+
+	is.Contains(report, filepath.Join(dir, ".git", "id_rsa"))
+	is.NotContains(report, "node_modules")
+	is.NotContains(report, "tls.pem")
+}
+
+func TestConvert(t *testing.T) {
+	is := require.New(t)
+	isolate(t)
+
+	modes := []struct {
+		mode     string
+		expected string
+	}{
+		{"755", "-rwxr-xr-x"},
+		{"rwxr-xr-x", "-rwxr-xr-x"},
+		{"drwxr-xr-x", "drwxr-xr-x"},
+		{"drwxr-xr-x.", "drwxr-xr-x"},
+		{"lrwxrwxrwx@", "lrwxrwxrwx"},
+	}
+
+	for _, v := range modes {
+		out := &bytes.Buffer{}
+
+		app := Execute()
+		app.Writer = out
+
+		is.NoError(app.Run([]string{"chmod-cli", "convert", v.mode}), v.mode)
+		is.Contains(out.String(), "ls -l:     "+v.expected+"\n", v.mode)
+	}
+}
+
+func TestWithoutHistory(t *testing.T) {
+	is := require.New(t)
+	isolate(t)
+
+	// without XDG_DATA_HOME and HOME there is no history file
+	for _, v := range []string{"XDG_DATA_HOME", "HOME"} {
+		old, ok := os.LookupEnv(v)
+
+		os.Unsetenv(v)
+
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(v, old)
+			}
+		})
+	}
+
+	out := &bytes.Buffer{}
+
+	app := Execute()
+	app.Reader = pipeKeys(t, "q")
+	app.Writer = out
+	app.ErrWriter = &bytes.Buffer{}
+
+	is.NoError(app.Run([]string{"chmod-cli", "--print", "--mode", "octal", "--preset", "Regular file"}))
+	is.Equal("chmod 644\n", out.String())
+}
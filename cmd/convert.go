@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/urfave/cli/v2"
+)
+
+func convertCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "convert",
+		Usage:     "convert a mode between octal and symbolic notation",
+		ArgsUsage: "<mode>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "directory",
+				Aliases: []string{"d"},
+				Usage:   "use the directory type in the ls-style output",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("convert expects exactly one mode argument, e.g 755 or rwxr-xr-x")
+			}
+
+			mode := strings.TrimSpace(c.Args().First())
+
+			state, err := generate.ParseMode(mode)
+			if err != nil {
+				return err
+			}
+
+			// conditional execute (X) has no octal form
+			octal, octalErr := state.BuildCommand("Octal")
+
+			symbolic, err := state.BuildCommand("Symbolic")
+			if err != nil {
+				return err
+			}
+
+			fileType := "-"
+
+			// ls -l modes may end with an attribute marker after the type and bits
+			if len(mode) == 10 || len(mode) == 11 {
+				fileType = mode[:1]
+			}
+
+			if c.Bool("directory") {
+				fileType = "d"
+			}
+
+			w := c.App.Writer
+
+			if octalErr != nil {
+				fmt.Fprintf(w, "%-10s %s\n", "Octal:", "n/a, conditional execute (X) has no octal form")
+			} else {
+				fmt.Fprintf(w, "%-10s %s\n", "Octal:", octal)
+			}
+
+			fmt.Fprintf(w, "%-10s %s\n", "Symbolic:", symbolic)
+			fmt.Fprintf(w, "%-10s %s%s\n", "ls -l:", fileType, symbolic)
+			fmt.Fprintln(w)
+
+			for i, name := range []string{"Owner:", "Group:", "Other:"} {
+				if octalErr != nil {
+					fmt.Fprintf(w, "%-10s %s\n", name, symbolic[i*3:i*3+3])
+					continue
+				}
+
+				fmt.Fprintf(w, "%-10s %s  %c\n", name, symbolic[i*3:i*3+3], octal[len(octal)-3+i])
+			}
+
+			fmt.Fprintf(w, "%-10s %s\n", "Special:", specialNames(state))
+
+			return nil
+		},
+	}
+}
+
+// specialNames lists the special bits set on a state
+func specialNames(state *generate.State) string {
+	names := []string{}
+
+	for _, s := range []generate.Special{generate.SetUID, generate.SetGID, generate.Sticky} {
+		if state.Special[s] {
+			names = append(names, string(s))
+		}
+	}
+
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/urfave/cli/v2"
+)
+
+// bitMeaning describes what a permission bit allows on files and directories
+type bitMeaning struct {
+	name      string
+	file      string
+	directory string
+}
+
+var accessMeanings = map[generate.Access]bitMeaning{
+	generate.ReadAccess: {
+		name:      "read",
+		file:      "read the file's contents",
+		directory: "list the names of entries in the directory",
+	},
+	generate.WriteAccess: {
+		name:      "write",
+		file:      "modify or truncate the file's contents",
+		directory: "create, delete and rename entries (needs execute too)",
+	},
+	generate.ExecuteAccess: {
+		name:      "execute",
+		file:      "run the file as a program or script",
+		directory: "enter the directory and access entries by name",
+	},
+	generate.ConditionalExecuteAccess: {
+		name:      "conditional execute (X)",
+		file:      "execute, but only if the file already has an execute bit set",
+		directory: "enter the directory and access entries by name",
+	},
+}
+
+var explainAccesses = []generate.Access{
+	generate.ReadAccess,
+	generate.WriteAccess,
+	generate.ExecuteAccess,
+	generate.ConditionalExecuteAccess,
+}
+
+var specialMeanings = map[generate.Special]bitMeaning{
+	generate.SetUID: {
+		name:      "setuid",
+		file:      "run the program with the privileges of the file's owner",
+		directory: "ignored on most systems",
+	},
+	generate.SetGID: {
+		name:      "setgid",
+		file:      "run the program with the privileges of the file's group",
+		directory: "new entries inherit the directory's group",
+	},
+	generate.Sticky: {
+		name:      "sticky",
+		file:      "ignored on modern systems",
+		directory: "only an entry's owner can delete or rename it",
+	},
+}
+
+var classes = []struct {
+	name    string
+	user    generate.User
+	special generate.Special
+}{
+	{"Owner", generate.Owner, generate.SetUID},
+	{"Group", generate.Group, generate.SetGID},
+	{"Other", generate.Other, generate.Sticky},
+}
+
+func explainCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "explain",
+		Usage:     "describe what a mode allows in plain English",
+		ArgsUsage: "<mode>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "path-type",
+				Aliases: []string{"t"},
+				Usage:   "only describe the meaning for a 'file' or 'directory'",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("explain expects exactly one mode argument, e.g 2770 or u=rwx,go=")
+			}
+
+			pathType := strings.ToLower(c.String("path-type"))
+
+			if pathType != "" && pathType != "file" && pathType != "directory" {
+				return fmt.Errorf("invalid path type '%s': expected 'file' or 'directory'", pathType)
+			}
+
+			mode := strings.TrimSpace(c.Args().First())
+			w := c.App.Writer
+
+			state, err := generate.ParseMode(mode)
+			if err == nil {
+				return explainAbsolute(w, mode, state, pathType)
+			}
+
+			if !generate.IsRelative(mode) {
+				return err
+			}
+
+			state, err = generate.ParseRelative(mode)
+			if err != nil {
+				return err
+			}
+
+			return explainRelative(w, mode, state, pathType)
+		},
+	}
+}
+
+func explainAbsolute(w io.Writer, mode string, state *generate.State, pathType string) error {
+	symbolic, err := state.BuildCommand("Symbolic")
+	if err != nil {
+		return err
+	}
+
+	// conditional execute has no octal form
+	if octal, err := state.BuildCommand("Octal"); err == nil {
+		fmt.Fprintf(w, "Mode %s (%s, %s)\n", mode, octal, symbolic)
+	} else {
+		fmt.Fprintf(w, "Mode %s (%s)\n", mode, symbolic)
+	}
+
+	for i, class := range classes {
+		fmt.Fprintf(w, "\n%s: %s\n", class.name, symbolic[i*3:i*3+3])
+
+		granted := 0
+
+		for _, a := range explainAccesses {
+			if state.Users[class.user][a] {
+				writeMeaning(w, "can", accessMeanings[a], pathType)
+				granted++
+			}
+		}
+
+		if state.Special[class.special] {
+			writeMeaning(w, "has", specialMeanings[class.special], pathType)
+
+			if !state.Users[class.user][generate.ExecuteAccess] && class.special != generate.Sticky {
+				fmt.Fprintf(w, "    note: %s has no effect without execute\n", specialMeanings[class.special].name)
+			}
+
+			granted++
+		}
+
+		if granted == 0 {
+			fmt.Fprintln(w, "  no access")
+		}
+	}
+
+	return nil
+}
+
+func explainRelative(w io.Writer, mode string, state *generate.State, pathType string) error {
+	normalized, err := state.BuildCommand("Relative")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Mode %s (relative, %s)\n", mode, normalized)
+
+	for _, class := range classes {
+		fmt.Fprintf(w, "\n%s:\n", class.name)
+
+		changed := 0
+
+		for _, a := range explainAccesses {
+			if verb := changeVerb(state.Changes[class.user][a]); verb != "" {
+				writeMeaning(w, verb, accessMeanings[a], pathType)
+				changed++
+			}
+		}
+
+		if verb := changeVerb(state.SpecialChanges[class.special]); verb != "" {
+			writeMeaning(w, verb, specialMeanings[class.special], pathType)
+			changed++
+		}
+
+		if changed == 0 {
+			fmt.Fprintln(w, "  unchanged")
+		}
+	}
+
+	return nil
+}
+
+func changeVerb(op generate.Operation) string {
+	switch op {
+	case generate.Add:
+		return "gains"
+
+	case generate.Remove:
+		return "loses"
+	}
+
+	return ""
+}
+
+func writeMeaning(w io.Writer, verb string, m bitMeaning, pathType string) {
+	fmt.Fprintf(w, "  %s %s\n", verb, m.name)
+
+	if pathType == "" || pathType == "file" {
+		fmt.Fprintf(w, "    file:      %s\n", m.file)
+	}
+
+	if pathType == "" || pathType == "directory" {
+		fmt.Fprintf(w, "    directory: %s\n", m.directory)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/history"
+	"github.com/urfave/cli/v2"
+)
+
+func historyCommand() *cli.Command {
+	searchFlag := &cli.StringFlag{
+		Name:    "search",
+		Aliases: []string{"s"},
+		Usage:   "only include commands, targets or directories containing `TEXT`",
+	}
+
+	return &cli.Command{
+		Name:  "history",
+		Usage: "browse, search and copy previously generated commands",
+		Flags: []cli.Flag{
+			searchFlag,
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Value:   20,
+				Usage:   "list at most `N` commands, 0 lists all of them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			entries, err := historyEntries(c)
+			if err != nil {
+				return err
+			}
+
+			w := c.App.Writer
+
+			if len(entries) == 0 {
+				fmt.Fprintln(w, "no commands in the history")
+				return nil
+			}
+
+			if limit := c.Int("limit"); limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+
+			for i, v := range entries {
+				fmt.Fprintf(w, "%3d  %s  %-5s  %s\n", i+1, v.Time.Local().Format("2006-01-02 15:04"), v.Action, v.OneLine())
+
+				if v.Dir != "" {
+					fmt.Fprintf(w, "     in %s\n", v.Dir)
+				}
+			}
+
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:      "copy",
+				Usage:     "copy a command to the clipboard and print it, 1 is the most recent",
+				ArgsUsage: "<number>",
+				Flags:     []cli.Flag{searchFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("copy expects exactly one history number, e.g copy 1")
+					}
+
+					n, err := strconv.Atoi(c.Args().First())
+					if err != nil || n < 1 {
+						return fmt.Errorf("invalid history number '%s'", c.Args().First())
+					}
+
+					entries, err := historyEntries(c)
+					if err != nil {
+						return err
+					}
+
+					if n > len(entries) {
+						return fmt.Errorf("no command number %d in the history", n)
+					}
+
+					command := entries[n-1].Command
+
+					fmt.Fprintln(c.App.Writer, command)
+
+					return common.CopyToClipboard(command)
+				},
+			},
+			{
+				Name:  "clear",
+				Usage: "remove all commands from the history",
+				Action: func(c *cli.Context) error {
+					path, err := history.UserHistoryPath()
+					if err != nil {
+						return err
+					}
+
+					if err := history.Clear(path); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.App.Writer, "cleared %s\n", path)
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// historyEntries returns the entries of the user history file, most recent
+// first, filtered by the search flag
+func historyEntries(c *cli.Context) ([]history.Entry, error) {
+	path, err := history.UserHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := history.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if query := c.String("search"); query != "" {
+		entries = history.Search(entries, query)
+	}
+
+	return entries, nil
+}
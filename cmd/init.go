@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// The widgets draw the tui on the terminal and read the command back from a
+// temporary file written with --output. The commands of a recursive find pair
+// are joined with && so they fit on one command line
+
+const bashInit = `# chmod-cli integration for bash, add this to ~/.bashrc:
+#   eval "$(chmod-cli init bash)"
+# press ctrl+x m to insert a chmod command at the cursor
+
+__chmod_cli_widget() {
+  local out cmd line
+  out="$(mktemp "${TMPDIR:-/tmp}/chmod-cli.XXXXXX")" || return
+
+  if chmod-cli --output "$out" </dev/tty >/dev/tty; then
+    while IFS= read -r line; do
+      cmd="${cmd:+$cmd && }$line"
+    done <"$out"
+
+    READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${cmd}${READLINE_LINE:READLINE_POINT}"
+    READLINE_POINT=$((READLINE_POINT + ${#cmd}))
+  fi
+
+  command rm -f "$out"
+}
+
+bind -m emacs-standard -x '"\C-xm": __chmod_cli_widget'
+bind -m vi-insert -x '"\C-xm": __chmod_cli_widget'
+`
+
+const zshInit = `# chmod-cli integration for zsh, add this to ~/.zshrc:
+#   eval "$(chmod-cli init zsh)"
+# press ctrl+x m to insert a chmod command at the cursor
+
+chmod-cli-widget() {
+  local out cmd line
+  out="$(mktemp "${TMPDIR:-/tmp}/chmod-cli.XXXXXX")" || return
+
+  if chmod-cli --output "$out" </dev/tty >/dev/tty; then
+    while IFS= read -r line; do
+      cmd="${cmd:+$cmd && }$line"
+    done <"$out"
+
+    LBUFFER+="$cmd"
+  fi
+
+  command rm -f "$out"
+  zle reset-prompt
+}
+
+zle -N chmod-cli-widget
+bindkey -M emacs '^Xm' chmod-cli-widget
+bindkey -M viins '^Xm' chmod-cli-widget
+`
+
+const fishInit = `# chmod-cli integration for fish, add this to ~/.config/fish/config.fish:
+#   chmod-cli init fish | source
+# press ctrl+x m to insert a chmod command at the cursor
+
+function chmod_cli_widget
+    set -l out (mktemp)
+    or return
+
+    if chmod-cli --output $out </dev/tty >/dev/tty
+        commandline -i -- (string join ' && ' <$out)
+    end
+
+    command rm -f $out
+    commandline -f repaint
+end
+
+bind \cxm chmod_cli_widget
+
+if bind -M insert >/dev/null 2>&1
+    bind -M insert \cxm chmod_cli_widget
+end
+`
+
+var shellInits = map[string]string{
+	"bash": bashInit,
+	"zsh":  zshInit,
+	"fish": fishInit,
+}
+
+func initCommand() *cli.Command {
+	shells := make([]string, 0, len(shellInits))
+
+	for k := range shellInits {
+		shells = append(shells, k)
+	}
+
+	sort.Strings(shells)
+
+	return &cli.Command{
+		Name:      "init",
+		Usage:     "print the shell integration script that binds ctrl+x m to insert a command at the prompt",
+		ArgsUsage: fmt.Sprintf("<%s>", strings.Join(shells, "|")),
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("init expects exactly one shell argument, e.g init zsh")
+			}
+
+			shell := strings.ToLower(c.Args().First())
+
+			script, ok := shellInits[shell]
+			if !ok {
+				return fmt.Errorf("unsupported shell '%s', expected one of %s", shell, strings.Join(shells, ", "))
+			}
+
+			fmt.Fprint(c.App.Writer, script)
+
+			return nil
+		},
+	}
+}
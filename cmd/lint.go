@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/lint"
+	"github.com/urfave/cli/v2"
+)
+
+func lintCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "lint",
+		Usage:     "check a mode for risky permissions, exits with 1 when issues are found",
+		ArgsUsage: "<mode>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "directory",
+				Aliases: []string{"d"},
+				Usage:   "lint the mode as a directory mode instead of a file mode",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("lint expects exactly one mode argument, e.g 755 or rwxr-xr-x")
+			}
+
+			state, err := generate.ParseMode(strings.TrimSpace(c.Args().First()))
+			if err != nil {
+				return err
+			}
+
+			pathType := "File"
+
+			if c.Bool("directory") {
+				pathType = "Directory"
+			}
+
+			findings := lint.Lint(state, pathType)
+			w := c.App.Writer
+
+			if len(findings) == 0 {
+				fmt.Fprintln(w, "no issues found")
+				return nil
+			}
+
+			for _, f := range findings {
+				fmt.Fprintf(w, "%-7s %-32s %s\n", f.Severity, f.Rule, f.Message)
+			}
+
+			return cli.Exit(fmt.Sprintf("%d issue(s) found", len(findings)), 1)
+		},
+	}
+}
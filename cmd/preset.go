@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/config"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/urfave/cli/v2"
+)
+
+func presetCommand() *cli.Command {
+	projectFlag := &cli.BoolFlag{
+		Name:    "project",
+		Aliases: []string{"p"},
+		Usage:   "use the project presets file (.chmod-cli/presets.yaml) instead of the user one",
+	}
+
+	return &cli.Command{
+		Name:  "preset",
+		Usage: "list and manage presets",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "list the built-in, user and project presets",
+				Action: func(c *cli.Context) error {
+					sources, err := config.LoadPresetSources()
+					if err != nil {
+						return err
+					}
+
+					w := c.App.Writer
+
+					for i, s := range sources {
+						if i > 0 {
+							fmt.Fprintln(w)
+						}
+
+						if s.Path != "" {
+							fmt.Fprintf(w, "%s (%s)\n", s.Name, s.Path)
+						} else {
+							fmt.Fprintln(w, s.Name)
+						}
+
+						if len(s.Presets) == 0 {
+							fmt.Fprintln(w, "  none")
+						}
+
+						for _, p := range s.Presets {
+							fmt.Fprintf(w, "  %-28s %-5s %s\n", p.Name, p.Mode, p.PathType)
+						}
+					}
+
+					return nil
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "show the mode of a preset",
+				ArgsUsage: "<name>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("show expects exactly one preset name")
+					}
+
+					sources, err := config.LoadPresetSources()
+					if err != nil {
+						return err
+					}
+
+					name := c.Args().First()
+					presets := config.MergePresets(sources)
+
+					i := config.FindPreset(presets, name)
+					if i < 0 {
+						return fmt.Errorf("no preset named '%s'", name)
+					}
+
+					return writePreset(c, presets[i], presetSource(sources, presets[i]))
+				},
+			},
+			{
+				Name:      "add",
+				Usage:     "add a preset to the user or project presets file",
+				ArgsUsage: "<name> <mode>",
+				Flags: []cli.Flag{
+					projectFlag,
+					&cli.StringFlag{
+						Name:    "path-type",
+						Aliases: []string{"t"},
+						Value:   "file",
+						Usage:   "the path type the preset selects, 'file' or 'directory'",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return errors.New("add expects a preset name and a mode, e.g add 'Deploy key' 400")
+					}
+
+					preset, err := config.NewPreset(c.Args().Get(0), c.Args().Get(1), c.String("path-type"))
+					if err != nil {
+						return err
+					}
+
+					path, presets, err := loadPresetsFile(c.Bool("project"))
+					if err != nil {
+						return err
+					}
+
+					if config.FindPreset(presets, preset.Name) >= 0 {
+						return fmt.Errorf("preset '%s' already exists in %s, remove it first", preset.Name, path)
+					}
+
+					if err := config.SavePresetsFile(path, append(presets, preset)); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.App.Writer, "added '%s' to %s\n", preset.Name, path)
+
+					return nil
+				},
+			},
+			{
+				Name:      "remove",
+				Usage:     "remove a preset from the user or project presets file",
+				ArgsUsage: "<name>",
+				Flags:     []cli.Flag{projectFlag},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return errors.New("remove expects exactly one preset name")
+					}
+
+					path, presets, err := loadPresetsFile(c.Bool("project"))
+					if err != nil {
+						return err
+					}
+
+					name := c.Args().First()
+
+					i := config.FindPreset(presets, name)
+					if i < 0 {
+						return fmt.Errorf("no preset named '%s' in %s, built-in presets can't be removed", name, path)
+					}
+
+					name = presets[i].Name
+
+					if err := config.SavePresetsFile(path, append(presets[:i], presets[i+1:]...)); err != nil {
+						return err
+					}
+
+					fmt.Fprintf(c.App.Writer, "removed '%s' from %s\n", name, path)
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// loadPresetsFile returns the path and presets of the user or project presets file
+func loadPresetsFile(project bool) (string, []generate.Preset, error) {
+	pathFunc := config.UserPresetsPath
+
+	if project {
+		pathFunc = config.ProjectPresetsPath
+	}
+
+	path, err := pathFunc()
+	if err != nil {
+		return "", nil, err
+	}
+
+	presets, err := config.LoadPresetsFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return path, presets, nil
+}
+
+// presetSource returns the name of the last source defining the preset, which
+// is the one that takes effect
+func presetSource(sources []config.PresetSource, preset generate.Preset) string {
+	name := ""
+
+	for _, s := range sources {
+		if config.FindPreset(s.Presets, preset.Name) >= 0 {
+			name = s.Name
+		}
+	}
+
+	return name
+}
+
+func writePreset(c *cli.Context, preset generate.Preset, source string) error {
+	state, err := preset.State()
+	if err != nil {
+		return err
+	}
+
+	symbolic, err := state.BuildCommand("Symbolic")
+	if err != nil {
+		return err
+	}
+
+	w := c.App.Writer
+
+	fmt.Fprintf(w, "%-11s %s\n", "Name:", preset.Name)
+	fmt.Fprintf(w, "%-11s %s\n", "Source:", source)
+	fmt.Fprintf(w, "%-11s %s\n", "Path type:", preset.PathType)
+	fmt.Fprintf(w, "%-11s %s\n", "Mode:", preset.Mode)
+	fmt.Fprintf(w, "%-11s %s\n", "Symbolic:", symbolic)
+	fmt.Fprintf(w, "%-11s %s\n", "Special:", specialNames(state))
+
+	return nil
+}
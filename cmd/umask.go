@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/urfave/cli/v2"
+)
+
+func umaskCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "umask",
+		Usage:     "show the default modes for a umask, or the umask that yields a mode",
+		ArgsUsage: "[umask]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "mode",
+				Aliases: []string{"m"},
+				Usage:   "compute the umask that gives new paths `MODE`",
+			},
+			&cli.BoolFlag{
+				Name:    "directory",
+				Aliases: []string{"d"},
+				Usage:   "treat --mode as the default for new directories instead of files",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			w := c.App.Writer
+
+			if mode := c.String("mode"); mode != "" {
+				if c.NArg() != 0 {
+					return errors.New("umask expects either a umask argument or --mode, not both")
+				}
+
+				pathType := "File"
+
+				if c.Bool("directory") {
+					pathType = "Directory"
+				}
+
+				state, err := generate.ParseMode(strings.TrimSpace(mode))
+				if err != nil {
+					return err
+				}
+
+				umask, err := state.Umask(pathType)
+				if err != nil {
+					return err
+				}
+
+				return writeDefaults(w, umask)
+			}
+
+			if c.NArg() != 1 {
+				return errors.New("umask expects exactly one umask argument (e.g 022) or --mode")
+			}
+
+			umask, err := generate.ParseUmask(strings.TrimSpace(c.Args().First()))
+			if err != nil {
+				return err
+			}
+
+			return writeDefaults(w, umask)
+		},
+	}
+}
+
+func writeDefaults(w io.Writer, umask fs.FileMode) error {
+	files, dirs := generate.DefaultModes(umask)
+
+	fmt.Fprintf(w, "%-13s %04o\n", "Umask:", uint32(umask))
+
+	for _, v := range []struct {
+		name  string
+		state *generate.State
+	}{
+		{"Files:", files},
+		{"Directories:", dirs},
+	} {
+		octal, err := v.state.BuildCommand("Octal")
+		if err != nil {
+			return err
+		}
+
+		symbolic, err := v.state.BuildCommand("Symbolic")
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "%-13s %s (%s)\n", v.name, octal, symbolic)
+	}
+
+	return nil
+}
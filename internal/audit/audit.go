@@ -0,0 +1,271 @@
+package audit
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/lint"
+)
+
+// Config selects the rules an audit runs and the names they match. Patterns
+// use filepath.Match syntax and are matched against base names
+type Config struct {
+	// Enable lists the rules to run, every rule runs when it's empty
+	Enable []string
+	// Disable lists the rules to skip
+	Disable []string
+	// KeyPatterns match private key files, which should only be accessible by their owner
+	KeyPatterns []string
+	// AllowSetuid match files that are expected to be setuid/setgid
+	AllowSetuid []string
+	// Exclude match entries to skip, excluded directories aren't descended into
+	Exclude []string
+}
+
+// DefaultConfig returns the config used when no flags are given
+func DefaultConfig() Config {
+	return Config{
+		KeyPatterns: []string{"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", "*.key", "*.p12", "*.pfx"},
+		Exclude:     []string{".git"},
+	}
+}
+
+// Rule checks the mode of a path, mode includes the type bits. Fix returns the
+// mode that resolves the violation
+type Rule struct {
+	Name     string
+	Severity lint.Severity
+	Check    func(path string, mode fs.FileMode) (string, bool)
+	Fix      func(mode fs.FileMode) fs.FileMode
+}
+
+// Finding is a single rule violation for a path
+type Finding struct {
+	Path     string        `json:"path"`
+	Rule     string        `json:"rule"`
+	Severity lint.Severity `json:"severity"`
+	Message  string        `json:"message"`
+	Mode     string        `json:"mode"`
+	Fix      string        `json:"fix"`
+}
+
+// PathError is an entry that couldn't be read during the walk
+type PathError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// Report is the result of an audit
+type Report struct {
+	Root     string      `json:"root"`
+	Scanned  int         `json:"scanned"`
+	Findings []Finding   `json:"findings"`
+	Errors   []PathError `json:"errors"`
+}
+
+const (
+	setuidBits = fs.ModeSetuid | fs.ModeSetgid
+	groupOther = fs.FileMode(0077)
+)
+
+// Rules returns every rule available to an audit: the mode rules from the
+// lint package followed by the path aware rules configured by c
+func Rules(c Config) []Rule {
+	rules := []Rule{}
+
+	for _, r := range lint.Rules {
+		r := r
+
+		rules = append(rules, Rule{
+			Name:     r.Name,
+			Severity: r.Severity,
+			Check: func(path string, mode fs.FileMode) (string, bool) {
+				return r.Check(mode, mode.IsDir())
+			},
+			Fix: func(mode fs.FileMode) fs.FileMode {
+				return r.Fix(mode, mode.IsDir())
+			},
+		})
+	}
+
+	return append(rules,
+		Rule{
+			Name:     "unexpected-setuid",
+			Severity: lint.Medium,
+			Check: func(path string, mode fs.FileMode) (string, bool) {
+				if mode.IsDir() || mode&setuidBits == 0 || matchAny(c.AllowSetuid, path) {
+					return "", false
+				}
+
+				return "setuid/setgid file that isn't in the allow list, it runs with its owner's or group's privileges", true
+			},
+			Fix: func(mode fs.FileMode) fs.FileMode {
+				return mode &^ setuidBits
+			},
+		},
+		Rule{
+			Name:     "private-key-permissions",
+			Severity: lint.High,
+			Check: func(path string, mode fs.FileMode) (string, bool) {
+				if mode.IsDir() || mode&groupOther == 0 || !matchAny(c.KeyPatterns, path) {
+					return "", false
+				}
+
+				return "private keys should only be accessible by their owner (600)", true
+			},
+			Fix: func(mode fs.FileMode) fs.FileMode {
+				return mode &^ groupOther
+			},
+		},
+	)
+}
+
+// SelectRules returns the rules enabled by c, in order
+func SelectRules(c Config) ([]Rule, error) {
+	rules := Rules(c)
+	names := make([]string, len(rules))
+
+	for i, r := range rules {
+		names[i] = r.Name
+	}
+
+	for _, name := range append(append([]string{}, c.Enable...), c.Disable...) {
+		if !common.IncludesString(names, name) {
+			return nil, fmt.Errorf("unknown rule '%s', available rules are: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	selected := []Rule{}
+
+	for _, r := range rules {
+		if len(c.Enable) > 0 && !common.IncludesString(c.Enable, r.Name) {
+			continue
+		}
+
+		if common.IncludesString(c.Disable, r.Name) {
+			continue
+		}
+
+		selected = append(selected, r)
+	}
+
+	return selected, nil
+}
+
+// Audit walks the tree at root and evaluates every file and directory against
+// the rules enabled by c. Symlinks and special files are skipped, entries that
+// can't be read are reported in Errors instead of stopping the walk
+func Audit(root string, c Config) (*Report, error) {
+	rules, err := SelectRules(c)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range append(append(append([]string{}, c.KeyPatterns...), c.AllowSetuid...), c.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+		}
+	}
+
+	report := &Report{
+		Root:     root,
+		Findings: []Finding{},
+		Errors:   []PathError{},
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// the root itself couldn't be read
+			if d == nil {
+				return err
+			}
+
+			report.Errors = append(report.Errors, PathError{Path: path, Error: err.Error()})
+
+			return nil
+		}
+
+		if path != root && matchAny(c.Exclude, path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			report.Errors = append(report.Errors, PathError{Path: path, Error: err.Error()})
+
+			return nil
+		}
+
+		mode := info.Mode()
+
+		if !mode.IsDir() && !mode.IsRegular() {
+			return nil
+		}
+
+		report.Scanned++
+
+		for _, r := range rules {
+			message, ok := r.Check(path, mode)
+			if !ok {
+				continue
+			}
+
+			finding, err := newFinding(path, mode, r, message)
+			if err != nil {
+				return err
+			}
+
+			report.Findings = append(report.Findings, finding)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func newFinding(path string, mode fs.FileMode, r Rule, message string) (Finding, error) {
+	octal, err := generate.NewStateFromMode(mode).BuildCommand("Octal")
+	if err != nil {
+		return Finding{}, err
+	}
+
+	// a relative fix only touches the offending bits, and unlike an octal mode
+	// it also clears setuid/setgid on directories with GNU chmod
+	fix, err := generate.NewChangesFromModes(mode, r.Fix(mode)).BuildCommand("Relative")
+	if err != nil {
+		return Finding{}, err
+	}
+
+	return Finding{
+		Path:     path,
+		Rule:     r.Name,
+		Severity: r.Severity,
+		Message:  message,
+		Mode:     octal,
+		Fix:      fmt.Sprintf("chmod %s %s", fix, common.QuotePath(path)),
+	}, nil
+}
+
+func matchAny(patterns []string, path string) bool {
+	name := filepath.Base(path)
+
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	is := require.New(t)
+
+	root := t.TempDir()
+
+	entries := []struct {
+		name string
+		mode os.FileMode
+		dir  bool
+	}{
+		{"shared", 0775, true},
+		{"shared/notes.txt", 0666, false},
+		{"shared/id_rsa", 0644, false},
+		{"shared/id_rsa.pub", 0644, false},
+		{"bin", 0755, true},
+		{"bin/tool", os.ModeSetuid | 0755, false},
+		{".git", 0777, true},
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(root, e.name)
+
+		if e.dir {
+			is.NoError(os.Mkdir(path, 0700))
+		} else {
+			is.NoError(os.WriteFile(path, nil, 0600))
+		}
+
+		// chmod after creating so the umask doesn't apply
+		is.NoError(os.Chmod(path, e.mode))
+	}
+
+	report, err := Audit(root, DefaultConfig())
+	is.NoError(err)
+
+	found := map[string]string{}
+
+	for _, f := range report.Findings {
+		rel, err := filepath.Rel(root, f.Path)
+		is.NoError(err)
+
+		found[rel+" "+f.Rule] = f.Fix
+	}
+
+	is.Equal(map[string]string{
+		"shared shared-directory-without-setgid": "chmod g+s " + filepath.Join(root, "shared"),
+		"shared/notes.txt world-writable":        "chmod o-w " + filepath.Join(root, "shared/notes.txt"),
+		"shared/id_rsa private-key-permissions":  "chmod go-r " + filepath.Join(root, "shared/id_rsa"),
+		"bin/tool unexpected-setuid":             "chmod u-s " + filepath.Join(root, "bin/tool"),
+	}, found)
+
+	c := DefaultConfig()
+	c.Disable = []string{"shared-directory-without-setgid"}
+	c.AllowSetuid = []string{"tool"}
+
+	report, err = Audit(root, c)
+	is.NoError(err)
+	is.Len(report.Findings, 2)
+
+	c.Enable = []string{"world-writable"}
+
+	report, err = Audit(root, c)
+	is.NoError(err)
+	is.Len(report.Findings, 1)
+	is.Equal("666", report.Findings[0].Mode)
+
+	c.Enable = []string{"no-such-rule"}
+
+	_, err = Audit(root, c)
+	is.Error(err)
+}
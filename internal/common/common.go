@@ -1,6 +1,10 @@
 package common
 
-import "github.com/atotto/clipboard"
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
 
 // IncludesString checks if a value exists in a slice of strings
 func IncludesString(s []string, val string) bool {
@@ -32,3 +36,23 @@ func CopyToClipboard(s string) error {
 
 	return nil
 }
+
+// ShellQuote quotes a string for safe use as a single shell word
+func ShellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=+,:@%") == "" {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// QuotePath quotes a path for use as a command argument. Relative paths
+// starting with - or = are prefixed with ./ so commands don't read them as
+// options and zsh doesn't expand them
+func QuotePath(path string) string {
+	if strings.HasPrefix(path, "-") || strings.HasPrefix(path, "=") {
+		path = "./" + path
+	}
+
+	return ShellQuote(path)
+}
@@ -35,3 +35,34 @@ func TestFindIndexString(t *testing.T) {
 		t.Errorf("Expected index to be '1', instead got '%d'", index)
 	}
 }
+
+func TestQuotePath(t *testing.T) {
+	quoted := map[string]string{
+		"deploy.sh":      "deploy.sh",
+		"-rf":            "./-rf",
+		"=ls":            "./=ls",
+		"-my file":       "'./-my file'",
+		"/srv/-releases": "/srv/-releases",
+	}
+
+	for s, expected := range quoted {
+		if got := QuotePath(s); got != expected {
+			t.Errorf("Expected '%s' to be quoted as '%s', instead got '%s'", s, expected, got)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	quoted := map[string]string{
+		"./bin/deploy.sh": "./bin/deploy.sh",
+		"my file.txt":     "'my file.txt'",
+		"it's":            `'it'\''s'`,
+		"":                "''",
+	}
+
+	for s, expected := range quoted {
+		if got := ShellQuote(s); got != expected {
+			t.Errorf("Expected '%s' to be quoted as '%s', instead got '%s'", s, expected, got)
+		}
+	}
+}
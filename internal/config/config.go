@@ -0,0 +1,66 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileName is the name of the config file in the user config directory
+const ConfigFileName = "config.yaml"
+
+// Config holds the defaults the tui starts with, empty fields keep the
+// built-in defaults. The values are validated by the tui, e.g
+//
+//	option: verbose
+//	mode: octal
+//	path-type: directory
+//	preset: Web root directory
+//	theme: ocean
+type Config struct {
+	Option   string `yaml:"option"`
+	Mode     string `yaml:"mode"`
+	PathType string `yaml:"path-type"`
+	Preset   string `yaml:"preset"`
+	Theme    string `yaml:"theme"`
+}
+
+// UserConfigPath returns the path of the config file in the user config directory
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, AppName, ConfigFileName), nil
+}
+
+// LoadConfigFile reads the config file at path, a missing file gives an empty config
+func LoadConfigFile(path string) (*Config, error) {
+	c := &Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// catch misspelled keys instead of silently ignoring them
+	dec.KnownFields(true)
+
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return c, nil
+}
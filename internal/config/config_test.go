@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPresetsFile(t *testing.T) {
+	is := require.New(t)
+
+	dir := t.TempDir()
+
+	presets, err := LoadPresetsFile(filepath.Join(dir, "missing.yaml"))
+	is.NoError(err)
+	is.Empty(presets)
+
+	files := []struct {
+		content string
+		valid   bool
+	}{
+		{"presets:\n  - name: Deploy key\n    mode: 0400\n", true},
+		{"presets:\n  - name: Release dir\n    mode: rwxr-s---\n    type: Directory\n", true},
+		{"presets:\n  - name: Broken\n    mode: 999\n", false},
+		{"presets:\n  - mode: 644\n", false},
+		{"presets:\n  - name: Relative\n    mode: u+x\n", false},
+		{"presets:\n  - name: Link\n    mode: 777\n    type: symlink\n", false},
+		{"presets:\n  - name: Twice\n    mode: 644\n  - name: twice\n    mode: 600\n", false},
+		{"presets: [", false},
+	}
+
+	for _, v := range files {
+		path := filepath.Join(dir, "presets.yaml")
+		is.NoError(os.WriteFile(path, []byte(v.content), 0644))
+
+		_, err := LoadPresetsFile(path)
+
+		if v.valid {
+			is.NoError(err, v.content)
+		} else {
+			is.Error(err, v.content)
+		}
+	}
+}
+
+func TestSavePresetsFile(t *testing.T) {
+	is := require.New(t)
+
+	path := filepath.Join(t.TempDir(), AppName, PresetsFileName)
+
+	presets := []generate.Preset{
+		{Name: "Deploy key", Mode: "400", PathType: "File"},
+		{Name: "Release dir", Mode: "2750", PathType: "Directory"},
+	}
+
+	is.NoError(SavePresetsFile(path, presets))
+
+	loaded, err := LoadPresetsFile(path)
+	is.NoError(err)
+	is.Equal(presets, loaded)
+}
+
+func TestMergePresets(t *testing.T) {
+	is := require.New(t)
+
+	merged := MergePresets([]PresetSource{
+		{Name: "built-in", Presets: generate.Presets},
+		{Name: "user", Presets: []generate.Preset{
+			{Name: "private key", Mode: "400", PathType: "File"},
+			{Name: "Deploy key", Mode: "400", PathType: "File"},
+		}},
+	})
+
+	is.Len(merged, len(generate.Presets)+1)
+	is.Equal("400", merged[FindPreset(merged, "Private key")].Mode)
+	is.Equal(-1, FindPreset(merged, "missing"))
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	is := require.New(t)
+
+	dir := t.TempDir()
+
+	c, err := LoadConfigFile(filepath.Join(dir, "missing.yaml"))
+	is.NoError(err)
+	is.Equal(&Config{}, c)
+
+	path := filepath.Join(dir, ConfigFileName)
+
+	is.NoError(os.WriteFile(path, []byte("option: verbose\nmode: octal\npath-type: directory\npreset: Web root directory\ntheme: ocean\n"), 0644))
+
+	c, err = LoadConfigFile(path)
+	is.NoError(err)
+	is.Equal(&Config{
+		Option:   "verbose",
+		Mode:     "octal",
+		PathType: "directory",
+		Preset:   "Web root directory",
+		Theme:    "ocean",
+	}, c)
+
+	is.NoError(os.WriteFile(path, nil, 0644))
+
+	c, err = LoadConfigFile(path)
+	is.NoError(err)
+	is.Equal(&Config{}, c)
+
+	is.NoError(os.WriteFile(path, []byte("pathtype: directory\n"), 0644))
+
+	_, err = LoadConfigFile(path)
+	is.Error(err)
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"gopkg.in/yaml.v3"
+)
+
+// AppName is the name of the directory the config files are kept in
+const AppName = "chmod-cli"
+
+// PresetsFileName is the name of the presets file in the user config
+// directory and in a project's .chmod-cli directory
+const PresetsFileName = "presets.yaml"
+
+// PresetSource is a place presets are loaded from
+type PresetSource struct {
+	Name    string
+	Path    string
+	Presets []generate.Preset
+}
+
+// presetsFile is the layout of a presets file, e.g
+//
+//	presets:
+//	  - name: Deploy key
+//	    mode: "400"
+//	    type: file
+type presetsFile struct {
+	Presets []presetEntry `yaml:"presets"`
+}
+
+type presetEntry struct {
+	Name string `yaml:"name"`
+	Mode string `yaml:"mode"`
+	Type string `yaml:"type,omitempty"`
+}
+
+// UserPresetsPath returns the path of the presets file in the user config directory
+func UserPresetsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, AppName, PresetsFileName), nil
+}
+
+// ProjectPresetsPath returns the path of the presets file for the project in
+// the working directory
+func ProjectPresetsPath() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "."+AppName, PresetsFileName), nil
+}
+
+// LoadPresetSources returns the built-in presets followed by the user and
+// project presets, sources without a presets file have no presets
+func LoadPresetSources() ([]PresetSource, error) {
+	sources := []PresetSource{{Name: "built-in", Presets: generate.Presets}}
+
+	for _, v := range []struct {
+		name string
+		path func() (string, error)
+	}{
+		{"user", UserPresetsPath},
+		{"project", ProjectPresetsPath},
+	} {
+		path, err := v.path()
+		if err != nil {
+			return nil, err
+		}
+
+		presets, err := LoadPresetsFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, PresetSource{Name: v.name, Path: path, Presets: presets})
+	}
+
+	return sources, nil
+}
+
+// MergePresets flattens the presets of the sources in order, a preset replaces
+// an earlier one with the same name in place
+func MergePresets(sources []PresetSource) []generate.Preset {
+	presets := []generate.Preset{}
+
+	for _, s := range sources {
+		for _, p := range s.Presets {
+			if i := FindPreset(presets, p.Name); i >= 0 {
+				presets[i] = p
+				continue
+			}
+
+			presets = append(presets, p)
+		}
+	}
+
+	return presets
+}
+
+// FindPreset returns the index of the preset with the given name, names are
+// compared case-insensitively
+func FindPreset(presets []generate.Preset, name string) int {
+	for i, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// LoadPresetsFile reads and validates the presets in the file at path, a
+// missing file has no presets
+func LoadPresetsFile(path string) ([]generate.Preset, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []generate.Preset{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	file := presetsFile{}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	presets := []generate.Preset{}
+
+	for _, entry := range file.Presets {
+		preset, err := NewPreset(entry.Name, entry.Mode, entry.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if FindPreset(presets, preset.Name) >= 0 {
+			return nil, fmt.Errorf("%s: duplicate preset '%s'", path, preset.Name)
+		}
+
+		presets = append(presets, preset)
+	}
+
+	return presets, nil
+}
+
+// SavePresetsFile writes the presets to the file at path, creating its directory
+func SavePresetsFile(path string, presets []generate.Preset) error {
+	file := presetsFile{Presets: []presetEntry{}}
+
+	for _, p := range presets {
+		file.Presets = append(file.Presets, presetEntry{
+			Name: p.Name,
+			Mode: p.Mode,
+			Type: strings.ToLower(p.PathType),
+		})
+	}
+
+	data := bytes.Buffer{}
+
+	enc := yaml.NewEncoder(&data)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(file); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data.Bytes(), 0644)
+}
+
+// NewPreset validates the fields of a preset, pathType is 'file' or
+// 'directory' and defaults to 'file'
+func NewPreset(name, mode, pathType string) (generate.Preset, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return generate.Preset{}, fmt.Errorf("preset with mode '%s' needs a name", mode)
+	}
+
+	preset := generate.Preset{
+		Name:     name,
+		Mode:     strings.TrimSpace(mode),
+		PathType: "File",
+	}
+
+	switch strings.ToLower(pathType) {
+	case "", "file":
+
+	case "directory":
+		preset.PathType = "Directory"
+
+	default:
+		return generate.Preset{}, fmt.Errorf("preset '%s': invalid type '%s': expected 'file' or 'directory'", name, pathType)
+	}
+
+	if _, err := preset.State(); err != nil {
+		return generate.Preset{}, err
+	}
+
+	return preset, nil
+}
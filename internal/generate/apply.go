@@ -0,0 +1,180 @@
+package generate
+
+import (
+	"io/fs"
+	"os"
+)
+
+var accessBits = map[Access]fs.FileMode{
+	ReadAccess:    4,
+	WriteAccess:   2,
+	ExecuteAccess: 1,
+}
+
+var userShift = map[User]uint{
+	Owner: 6,
+	Group: 3,
+	Other: 0,
+}
+
+var specialBits = map[Special]fs.FileMode{
+	SetUID: fs.ModeSetuid,
+	SetGID: fs.ModeSetgid,
+	Sticky: fs.ModeSticky,
+}
+
+// FileMode returns the permission and special bits of the state as an fs.FileMode
+func (s *State) FileMode() fs.FileMode {
+	var mode fs.FileMode
+
+	for _, u := range users {
+		for _, a := range accesses {
+			if s.Users[u][a] {
+				mode |= accessBits[a] << userShift[u]
+			}
+		}
+	}
+
+	for special, bit := range specialBits {
+		if s.Special[special] {
+			mode |= bit
+		}
+	}
+
+	return mode
+}
+
+// NewStateFromMode returns a state with the permission and special bits of mode
+func NewStateFromMode(mode fs.FileMode) *State {
+	s := NewState()
+
+	for _, u := range users {
+		for _, a := range accesses {
+			s.Users[u][a] = mode&(accessBits[a]<<userShift[u]) != 0
+		}
+	}
+
+	for special, bit := range specialBits {
+		s.Special[special] = mode&bit != 0
+	}
+
+	return s
+}
+
+// NewChangesFromModes returns a state with the relative changes that turn mode
+// from into mode to, unaffected bits are left unchanged
+func NewChangesFromModes(from, to fs.FileMode) *State {
+	s := NewState()
+
+	for _, u := range users {
+		for _, a := range accesses {
+			bit := accessBits[a] << userShift[u]
+			s.Changes[u][a] = modeChange(from&bit != 0, to&bit != 0)
+		}
+	}
+
+	for special, bit := range specialBits {
+		s.SpecialChanges[special] = modeChange(from&bit != 0, to&bit != 0)
+	}
+
+	return s
+}
+
+func modeChange(from, to bool) Operation {
+	switch {
+	case !from && to:
+		return Add
+
+	case from && !to:
+		return Remove
+	}
+
+	return Unchanged
+}
+
+// conditional reports whether conditional execute (X) applies to a mode, which
+// is the case for directories and files with an execute bit already set
+func conditional(mode fs.FileMode) bool {
+	return mode.IsDir() || mode&0111 != 0
+}
+
+// ApplyChanges applies the relative changes of the state to an existing mode
+func (s *State) ApplyChanges(mode fs.FileMode) fs.FileMode {
+	applyX := conditional(mode)
+	mode &= fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+	for _, u := range users {
+		for _, a := range accesses {
+			switch s.Changes[u][a] {
+			case Add:
+				mode |= accessBits[a] << userShift[u]
+
+			case Remove:
+				mode &^= accessBits[a] << userShift[u]
+			}
+		}
+
+		if !applyX {
+			continue
+		}
+
+		switch s.Changes[u][ConditionalExecuteAccess] {
+		case Add:
+			mode |= accessBits[ExecuteAccess] << userShift[u]
+
+		case Remove:
+			mode &^= accessBits[ExecuteAccess] << userShift[u]
+		}
+	}
+
+	for special, bit := range specialBits {
+		switch s.SpecialChanges[special] {
+		case Add:
+			mode |= bit
+
+		case Remove:
+			mode &^= bit
+		}
+	}
+
+	return mode
+}
+
+// ResolveMode returns the mode a path would end up with when the state is
+// applied in the given command mode. Relative changes are applied on top of
+// current, absolute modes replace it
+func (s *State) ResolveMode(mode string, current fs.FileMode) fs.FileMode {
+	if mode == "Relative" {
+		return s.ApplyChanges(current)
+	}
+
+	resolved := s.FileMode()
+
+	if !conditional(current) {
+		return resolved
+	}
+
+	for _, u := range users {
+		if s.Users[u][ConditionalExecuteAccess] {
+			resolved |= accessBits[ExecuteAccess] << userShift[u]
+		}
+	}
+
+	return resolved
+}
+
+// ApplyMode changes the mode of path to match the state and returns the new mode
+func (s *State) ApplyMode(path string, mode string) (fs.FileMode, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := s.ResolveMode(mode, stat.Mode())
+
+	if err := os.Chmod(path, resolved); err != nil {
+		return 0, err
+	}
+
+	return resolved, nil
+}
@@ -2,6 +2,7 @@ package generate
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"sort"
@@ -14,6 +15,10 @@ const (
 	ReadAccess    = Access("r")
 	WriteAccess   = Access("w")
 	ExecuteAccess = Access("x")
+
+	// ConditionalExecuteAccess only grants execute to directories and to files
+	// that already have an execute bit set. It has no octal equivalent
+	ConditionalExecuteAccess = Access("X")
 )
 
 type User string
@@ -24,10 +29,22 @@ const (
 	Other = User("other")
 )
 
+// Special represents one of the setuid, setgid and sticky bits
+type Special string
+
+const (
+	SetUID = Special("setuid")
+	SetGID = Special("setgid")
+	Sticky = Special("sticky")
+)
+
 type State struct {
-	Users   map[User]map[Access]bool
-	Command string
-	PWD     string
+	Users          map[User]map[Access]bool
+	Special        map[Special]bool
+	Changes        map[User]map[Access]Operation
+	SpecialChanges map[Special]Operation
+	Command        string
+	PWD            string
 }
 
 func NewState() *State {
@@ -49,6 +66,17 @@ func NewState() *State {
 				ExecuteAccess: false,
 			},
 		},
+		Special: map[Special]bool{
+			SetUID: false,
+			SetGID: false,
+			Sticky: false,
+		},
+		Changes: map[User]map[Access]Operation{
+			Owner: {},
+			Group: {},
+			Other: {},
+		},
+		SpecialChanges: map[Special]Operation{},
 	}
 }
 
@@ -58,7 +86,12 @@ func GetPWDMode() (fs.FileMode, error) {
 		return 0, err
 	}
 
-	stat, err := os.Stat(pwd)
+	return GetPathMode(pwd)
+}
+
+// GetPathMode returns the mode of the file or directory at path
+func GetPathMode(path string) (fs.FileMode, error) {
+	stat, err := os.Stat(path)
 	if err != nil {
 		return 0, err
 	}
@@ -66,7 +99,29 @@ func GetPWDMode() (fs.FileMode, error) {
 	return stat.Mode(), nil
 }
 
-func (s *State) BuildCommand(mode string) string {
+// ModeArgument renders the state as the mode argument of a chmod command in
+// the given command mode
+func (s *State) ModeArgument(mode string) (string, error) {
+	built, err := s.BuildCommand(mode)
+	if err != nil {
+		return "", err
+	}
+
+	// the symbolic display form isn't a valid chmod argument
+	if mode == "Symbolic" {
+		return s.Expression(), nil
+	}
+
+	return built, nil
+}
+
+// BuildCommand renders the state in the given command mode ("Octal", "Symbolic"
+// or "Relative")
+func (s *State) BuildCommand(mode string) (string, error) {
+	if mode == "Relative" {
+		return s.buildRelative(), nil
+	}
+
 	command := strings.Builder{}
 	owner := strings.Builder{}
 	group := strings.Builder{}
@@ -77,6 +132,10 @@ func (s *State) BuildCommand(mode string) string {
 	sortedOtherKeys := s.SortKeys(s.Users[Other])
 
 	for _, v := range sortedOwnerKeys {
+		if v == ConditionalExecuteAccess {
+			continue
+		}
+
 		if s.Users[Owner][v] {
 			owner.WriteString(string(v))
 		} else {
@@ -85,6 +144,10 @@ func (s *State) BuildCommand(mode string) string {
 	}
 
 	for _, v := range sortedGroupKeys {
+		if v == ConditionalExecuteAccess {
+			continue
+		}
+
 		if s.Users[Group][v] {
 			group.WriteString(string(v))
 		} else {
@@ -93,6 +156,10 @@ func (s *State) BuildCommand(mode string) string {
 	}
 
 	for _, v := range sortedOtherKeys {
+		if v == ConditionalExecuteAccess {
+			continue
+		}
+
 		if s.Users[Other][v] {
 			other.WriteString(string(v))
 		} else {
@@ -100,20 +167,80 @@ func (s *State) BuildCommand(mode string) string {
 		}
 	}
 
-	command.WriteString(owner.String())
-	command.WriteString(group.String())
-	command.WriteString(other.String())
+	for _, u := range users {
+		special := classSpecial[u]
 
-	if mode == "Octal" {
-		return toOctal(command.String())
+		if u == Other {
+			special = Sticky
+		}
+
+		if s.isConditional(u) && s.Special[special] {
+			return "", fmt.Errorf("%s cannot be combined with conditional execute (X) for %s", special, u)
+		}
 	}
 
-	return command.String()
+	command.WriteString(s.applySpecial(s.applyConditional(owner.String(), Owner), s.Special[SetUID], "s"))
+	command.WriteString(s.applySpecial(s.applyConditional(group.String(), Group), s.Special[SetGID], "s"))
+	command.WriteString(s.applySpecial(s.applyConditional(other.String(), Other), s.Special[Sticky], "t"))
+
+	switch mode {
+	case "Octal":
+		for _, u := range users {
+			if s.isConditional(u) {
+				return "", errors.New("octal mode cannot express conditional execute (X), use Symbolic or Relative")
+			}
+		}
+
+		return ToOctal(command.String())
+
+	case "Symbolic":
+		return command.String(), nil
+	}
+
+	return "", fmt.Errorf("unknown command mode '%s'", mode)
+}
+
+// isConditional reports whether a class has conditional execute without execute
+func (s *State) isConditional(u User) bool {
+	return s.Users[u][ConditionalExecuteAccess] && !s.Users[u][ExecuteAccess]
+}
+
+// applyConditional replaces the execute position of a permission triplet with X
+// when the class only has conditional execute
+func (s *State) applyConditional(triplet string, u User) string {
+	if !s.isConditional(u) {
+		return triplet
+	}
+
+	return triplet[:2] + string(ConditionalExecuteAccess)
+}
+
+// applySpecial replaces the execute position of a permission triplet with the
+// special bit symbol, lowercase if execute is set and uppercase otherwise
+func (s *State) applySpecial(triplet string, active bool, symbol string) string {
+	if !active {
+		return triplet
+	}
+
+	if triplet[2] == '-' {
+		return triplet[:2] + strings.ToUpper(symbol)
+	}
+
+	return triplet[:2] + symbol
 }
 
-func toOctal(cmd string) string {
+// ToOctal converts a nine character symbolic mode (rwxr-xr-x) to octal
+func ToOctal(cmd string) (string, error) {
 	if len(cmd) != 9 {
-		panic(errors.New("invalid chmod string"))
+		return "", fmt.Errorf("invalid symbolic mode '%s': expected 9 characters, got %d", cmd, len(cmd))
+	}
+
+	if strings.ContainsRune(cmd, 'X') {
+		return "", fmt.Errorf("invalid symbolic mode '%s': octal modes cannot express conditional execute (X)", cmd)
+	}
+
+	if _, err := parseSymbolic(cmd); err != nil {
+		return "", err
 	}
 
 	mapBinaryToDecimal := map[string]string{
@@ -128,13 +255,24 @@ func toOctal(cmd string) string {
 	}
 
 	binary := strings.Builder{}
+	special := strings.Builder{}
 
-	for _, s := range cmd {
-		if string(s) == "-" {
+	for i, s := range cmd {
+		if string(s) == "-" || string(s) == "S" || string(s) == "T" {
 			binary.WriteString("0")
 		} else {
 			binary.WriteString("1")
 		}
+
+		if i%3 != 2 {
+			continue
+		}
+
+		if strings.ContainsRune("sStT", s) {
+			special.WriteString("1")
+		} else {
+			special.WriteString("0")
+		}
 	}
 
 	var owner, group, other string
@@ -145,11 +283,29 @@ func toOctal(cmd string) string {
 
 	octal := strings.Builder{}
 
+	if special.String() != "000" {
+		octal.WriteString(mapBinaryToDecimal[special.String()])
+	}
+
 	octal.WriteString(mapBinaryToDecimal[owner])
 	octal.WriteString(mapBinaryToDecimal[group])
 	octal.WriteString(mapBinaryToDecimal[other])
 
-	return octal.String()
+	return octal.String(), nil
+}
+
+// ToSymbolic converts a three or four digit octal mode (755, 2775) to symbolic
+func ToSymbolic(octal string) (string, error) {
+	if !isNumeric(octal) {
+		return "", fmt.Errorf("invalid octal mode '%s': only digits are allowed", octal)
+	}
+
+	s, err := parseOctal(octal)
+	if err != nil {
+		return "", err
+	}
+
+	return s.BuildCommand("Symbolic")
 }
 
 func (s *State) SortKeys(m map[Access]bool) []Access {
@@ -1,6 +1,9 @@
 package generate
 
 import (
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -41,14 +44,41 @@ func TestSortKeys(t *testing.T) {
 }
 
 func TestToOctal(t *testing.T) {
-	// is := require.New(t)
+	is := require.New(t)
 
 	cmd := "rw-rwxr-x"
 	expected := "675"
 
-	if got := toOctal(cmd); got != expected {
+	got, err := ToOctal(cmd)
+	is.NoError(err)
+
+	if got != expected {
 		t.Errorf("Expected octal value to be '%s', instead got '%s'", expected, got)
 	}
+
+	_, err = ToOctal("rw-rwx")
+	is.Error(err)
+
+	_, err = ToOctal("rw-rwxr-q")
+	is.Error(err)
+}
+
+func TestToSymbolic(t *testing.T) {
+	is := require.New(t)
+
+	got, err := ToSymbolic("4750")
+	is.NoError(err)
+
+	expected := "rwsr-x---"
+	if got != expected {
+		t.Errorf("Expected symbolic value to be '%s', instead got '%s'", expected, got)
+	}
+
+	_, err = ToSymbolic("rwx")
+	is.Error(err)
+
+	_, err = ToSymbolic("758")
+	is.Error(err)
 }
 
 func TestBuildCommand(t *testing.T) {
@@ -66,11 +96,362 @@ func TestBuildCommand(t *testing.T) {
 	s.Users[Other][ReadAccess] = true
 	s.Users[Other][ExecuteAccess] = true
 
-	cmd := s.BuildCommand("Symbolic")
+	cmd, err := s.BuildCommand("Symbolic")
+	is.NoError(err)
 	is.NotEmpty(cmd)
 
 	expected := "rw-rwxr-x"
 	if cmd != expected {
 		t.Errorf("Expected command to be '%s', instead got '%s'", expected, cmd)
 	}
+
+	_, err = s.BuildCommand("Hexadecimal")
+	is.Error(err)
+}
+
+func TestBuildCommandSpecial(t *testing.T) {
+	is := require.New(t)
+
+	s := NewState()
+
+	s.Users[Owner][ReadAccess] = true
+	s.Users[Owner][WriteAccess] = true
+	s.Users[Owner][ExecuteAccess] = true
+
+	s.Users[Group][ReadAccess] = true
+	s.Users[Group][ExecuteAccess] = true
+
+	s.Users[Other][ReadAccess] = true
+
+	s.Special[SetUID] = true
+	s.Special[SetGID] = true
+	s.Special[Sticky] = true
+
+	cmd, err := s.BuildCommand("Symbolic")
+	is.NoError(err)
+	is.NotEmpty(cmd)
+
+	expected := "rwsr-sr-T"
+	if cmd != expected {
+		t.Errorf("Expected command to be '%s', instead got '%s'", expected, cmd)
+	}
+
+	cmd, err = s.BuildCommand("Octal")
+	is.NoError(err)
+
+	expected = "7754"
+	if cmd != expected {
+		t.Errorf("Expected command to be '%s', instead got '%s'", expected, cmd)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	is := require.New(t)
+
+	modes := map[string]string{
+		"750":         "rwxr-x---",
+		"2775":        "rwxrwsr-x",
+		"1777":        "rwxrwxrwt",
+		"rw-r--r--":   "rw-r--r--",
+		"drwsr-S--T":  "rwsr-S--T",
+		"drwxr-xr-x.": "rwxr-xr-x",
+		"-rw-r-----+": "rw-r-----",
+		"-rwxr-xr-x@": "rwxr-xr-x",
+	}
+
+	for mode, expected := range modes {
+		s, err := ParseMode(mode)
+		is.NoError(err)
+
+		got, err := s.BuildCommand("Symbolic")
+		is.NoError(err)
+
+		if got != expected {
+			t.Errorf("Expected '%s' to parse as '%s', instead got '%s'", mode, expected, got)
+		}
+	}
+
+	invalid := []string{"", "75", "789", "12345", "rwxr-x", "rwxr-xr-q", "zrwxr-xr-x", "rwtr-xr-x", "rwxr-xr-x.", "drwxr-xr-x*", "drwxr-xr-x.."}
+
+	for _, mode := range invalid {
+		_, err := ParseMode(mode)
+		is.Error(err, mode)
+	}
+}
+
+func TestBuildCommandRelative(t *testing.T) {
+	is := require.New(t)
+
+	s := NewState()
+
+	cmd, err := s.BuildCommand("Relative")
+	is.NoError(err)
+	is.Empty(cmd)
+
+	s.Changes[Owner][ExecuteAccess] = Add
+	s.Changes[Group][WriteAccess] = Add
+	s.Changes[Other][ReadAccess] = Remove
+	s.Changes[Other][WriteAccess] = Remove
+	s.Changes[Other][ExecuteAccess] = Remove
+
+	cmd, err = s.BuildCommand("Relative")
+	is.NoError(err)
+
+	expected := "u+x,g+w,o-rwx"
+	if cmd != expected {
+		t.Errorf("Expected command to be '%s', instead got '%s'", expected, cmd)
+	}
+
+	s = NewState()
+
+	s.Changes[Owner][ExecuteAccess] = Add
+	s.Changes[Group][ExecuteAccess] = Add
+	s.Changes[Other][ExecuteAccess] = Add
+	s.Changes[Group][WriteAccess] = Remove
+	s.Changes[Other][WriteAccess] = Remove
+	s.SpecialChanges[SetGID] = Add
+	s.SpecialChanges[Sticky] = Remove
+
+	cmd, err = s.BuildCommand("Relative")
+	is.NoError(err)
+
+	expected = "a+x,g+s,go-w,-t"
+	if cmd != expected {
+		t.Errorf("Expected command to be '%s', instead got '%s'", expected, cmd)
+	}
+}
+
+func TestParseRelative(t *testing.T) {
+	is := require.New(t)
+
+	modes := map[string]string{
+		"g+w,o-rwx": "g+w,o-rwx",
+		"u=rw,go=":  "u+rw,a-x,ug-s,go-rw",
+		"+x":        "a+x",
+		"ug+s,+t":   "ug+s,+t",
+		"u+r-x":     "u+r,u-x",
+	}
+
+	for mode, expected := range modes {
+		s, err := ParseRelative(mode)
+		is.NoError(err)
+
+		got, err := s.BuildCommand("Relative")
+		is.NoError(err)
+
+		if got != expected {
+			t.Errorf("Expected '%s' to parse as '%s', instead got '%s'", mode, expected, got)
+		}
+	}
+
+	invalid := []string{"", "u", "z+x", "u+q", "u+x,"}
+
+	for _, mode := range invalid {
+		_, err := ParseRelative(mode)
+		is.Error(err, mode)
+	}
+
+	is.True(IsRelative("go-w"))
+	is.True(IsRelative("u=rw"))
+	is.False(IsRelative("rwxr-xr-x"))
+	is.False(IsRelative("-rwxrwxrwx"))
+	is.False(IsRelative("-rw-r--r--"))
+	is.True(IsRelative("a-x"))
+	is.True(IsRelative("-x,+r"))
+	is.False(IsRelative("755"))
+}
+
+func TestApplyMode(t *testing.T) {
+	is := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "script.sh")
+	is.NoError(os.WriteFile(path, []byte("#!/bin/sh\n"), 0644))
+
+	s, err := ParseMode("750")
+	is.NoError(err)
+
+	mode, err := s.ApplyMode(path, "Octal")
+	is.NoError(err)
+	is.Equal(fs.FileMode(0750), mode)
+
+	stat, err := os.Stat(path)
+	is.NoError(err)
+	is.Equal(fs.FileMode(0750), stat.Mode().Perm())
+
+	s, err = ParseRelative("g+w,o+r")
+	is.NoError(err)
+
+	mode, err = s.ApplyMode(path, "Relative")
+	is.NoError(err)
+	is.Equal(fs.FileMode(0774), mode)
+
+	_, err = s.ApplyMode(filepath.Join(t.TempDir(), "missing"), "Relative")
+	is.Error(err)
+}
+
+func TestNewStateFromMode(t *testing.T) {
+	is := require.New(t)
+
+	s := NewStateFromMode(fs.ModeDir | fs.ModeSetgid | 0775)
+
+	cmd, err := s.BuildCommand("Octal")
+	is.NoError(err)
+	is.Equal("2775", cmd)
+	is.Equal(fs.ModeSetgid|0775, s.FileMode())
+}
+
+func TestNewChangesFromModes(t *testing.T) {
+	is := require.New(t)
+
+	modes := []struct {
+		from     fs.FileMode
+		to       fs.FileMode
+		expected string
+	}{
+		{0666, 0664, "o-w"},
+		{0644, 0600, "go-r"},
+		{fs.ModeSetuid | 0755, 0755, "u-s"},
+		{0775, fs.ModeSetgid | 0775, "g+s"},
+		{0711, 0755, "go+r"},
+		{0777, fs.ModeSticky | 0777, "+t"},
+	}
+
+	for _, v := range modes {
+		s := NewChangesFromModes(v.from, v.to)
+
+		cmd, err := s.BuildCommand("Relative")
+		is.NoError(err)
+		is.Equal(v.expected, cmd)
+		is.Equal(v.to, s.ApplyChanges(v.from))
+	}
+}
+
+func TestRecursiveExpression(t *testing.T) {
+	is := require.New(t)
+
+	files, err := ParseMode("644")
+	is.NoError(err)
+
+	dirs, err := ParseMode("755")
+	is.NoError(err)
+
+	expr, err := RecursiveExpression(files, dirs)
+	is.NoError(err)
+	is.Equal("u=rwX,go=rX", expr)
+
+	dirs, err = ParseMode("3775")
+	is.NoError(err)
+
+	_, err = RecursiveExpression(files, dirs)
+	is.Error(err)
+
+	s, err := ParseMode("1750")
+	is.NoError(err)
+	is.Equal("u=rwx,g=rx,o=,+t", s.Expression())
+
+	// the mode argument of a symbolic command is the expression
+	for mode, expected := range map[string]string{"Octal": "1750", "Symbolic": "u=rwx,g=rx,o=,+t"} {
+		arg, err := s.ModeArgument(mode)
+		is.NoError(err)
+		is.Equal(expected, arg)
+	}
+
+	cmds := FindCommands(".", "--verbose", "755", "644")
+	is.Equal("find . -type d -exec chmod --verbose 755 {} +", cmds[0])
+	is.Equal("find . -type f -exec chmod --verbose 644 {} +", cmds[1])
+
+	// a relative mode without changes has no command
+	is.Equal([]string{"find . -type f -exec chmod g+w {} +"}, FindCommands(".", "", "", "g+w"))
+	is.Empty(FindCommands(".", "", "", ""))
+}
+
+func TestConditionalExecute(t *testing.T) {
+	is := require.New(t)
+
+	s := NewState()
+
+	s.Users[Owner][ReadAccess] = true
+	s.Users[Owner][WriteAccess] = true
+	s.Users[Owner][ConditionalExecuteAccess] = true
+	s.Users[Group][ReadAccess] = true
+	s.Users[Group][ConditionalExecuteAccess] = true
+	s.Users[Other][ReadAccess] = true
+	s.Users[Other][ExecuteAccess] = true
+	s.Users[Other][ConditionalExecuteAccess] = true
+
+	cmd, err := s.BuildCommand("Symbolic")
+	is.NoError(err)
+	is.Equal("rwXr-Xr-x", cmd)
+
+	_, err = s.BuildCommand("Octal")
+	is.Error(err)
+
+	is.Equal(fs.FileMode(0755), s.ResolveMode("Symbolic", fs.ModeDir))
+	is.Equal(fs.FileMode(0645), s.ResolveMode("Symbolic", 0644))
+
+	s.Special[SetGID] = true
+
+	_, err = s.BuildCommand("Symbolic")
+	is.Error(err)
+
+	s, err = ParseRelative("u=rwX,go=")
+	is.NoError(err)
+
+	cmd, err = s.BuildCommand("Relative")
+	is.NoError(err)
+	is.Equal("u+rwX,ug-s,go-rwx", cmd)
+
+	is.Equal(fs.ModeDir|0700, fs.ModeDir|s.ApplyChanges(fs.ModeDir|0755))
+	is.Equal(fs.FileMode(0600), s.ApplyChanges(0644))
+}
+
+func TestUmask(t *testing.T) {
+	is := require.New(t)
+
+	s, err := ParseMode("640")
+	is.NoError(err)
+
+	umask, err := s.Umask("File")
+	is.NoError(err)
+	is.Equal(fs.FileMode(0026), umask)
+
+	s, err = ParseMode("750")
+	is.NoError(err)
+
+	umask, err = s.Umask("Directory")
+	is.NoError(err)
+	is.Equal(fs.FileMode(0027), umask)
+
+	_, err = s.Umask("File")
+	is.Error(err)
+
+	umask, err = ParseUmask("0022")
+	is.NoError(err)
+
+	files, dirs := DefaultModes(umask)
+	is.Equal(fs.FileMode(0644), files.FileMode())
+	is.Equal(fs.FileMode(0755), dirs.FileMode())
+
+	_, err = ParseUmask("1022")
+	is.Error(err)
+
+	_, err = ParseUmask("u=rwx")
+	is.Error(err)
+}
+
+func TestPresets(t *testing.T) {
+	is := require.New(t)
+
+	for _, p := range Presets {
+		s, err := p.State()
+		is.NoError(err)
+
+		octal, err := s.BuildCommand("Octal")
+		is.NoError(err)
+		is.Equal(p.Mode, octal)
+		is.Contains([]string{"File", "Directory"}, p.PathType)
+	}
+
+	_, err := Preset{Name: "Broken", Mode: "abc"}.State()
+	is.Error(err)
 }
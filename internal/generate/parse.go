@@ -0,0 +1,137 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// fileTypes holds the leading type characters printed by ls -l
+const fileTypes = "-dlbcps"
+
+// attributeMarkers holds the trailing characters ls -l prints when a path has
+// an SELinux context (.), an ACL (+) or extended attributes on macOS (@)
+const attributeMarkers = ".+@"
+
+var users = []User{Owner, Group, Other}
+
+var accesses = []Access{ReadAccess, WriteAccess, ExecuteAccess}
+
+// ParseMode parses an octal (755, 2775), symbolic (rwxr-xr-x) or ls -l style
+// (drwxr-xr-x, drwxr-xr-x.) mode into a State
+func ParseMode(mode string) (*State, error) {
+	mode = strings.TrimSpace(mode)
+
+	if mode == "" {
+		return nil, errors.New("mode cannot be empty")
+	}
+
+	if isNumeric(mode) {
+		return parseOctal(mode)
+	}
+
+	return parseSymbolic(mode)
+}
+
+func isNumeric(mode string) bool {
+	for _, c := range mode {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseOctal(mode string) (*State, error) {
+	if len(mode) != 3 && len(mode) != 4 {
+		return nil, fmt.Errorf("invalid octal mode '%s': expected 3 or 4 digits, got %d", mode, len(mode))
+	}
+
+	for _, c := range mode {
+		if c > '7' {
+			return nil, fmt.Errorf("invalid octal mode '%s': '%c' is not an octal digit", mode, c)
+		}
+	}
+
+	s := NewState()
+
+	if len(mode) == 4 {
+		special := mode[0] - '0'
+
+		s.Special[SetUID] = special&4 != 0
+		s.Special[SetGID] = special&2 != 0
+		s.Special[Sticky] = special&1 != 0
+
+		mode = mode[1:]
+	}
+
+	for i, u := range users {
+		digit := mode[i] - '0'
+
+		s.Users[u][ReadAccess] = digit&4 != 0
+		s.Users[u][WriteAccess] = digit&2 != 0
+		s.Users[u][ExecuteAccess] = digit&1 != 0
+	}
+
+	return s, nil
+}
+
+func parseSymbolic(mode string) (*State, error) {
+	input := mode
+
+	if len(mode) == 11 && strings.ContainsRune(attributeMarkers, rune(mode[10])) {
+		mode = mode[:10]
+	}
+
+	switch len(mode) {
+	case 9:
+	case 10:
+		if !strings.ContainsRune(fileTypes, rune(mode[0])) {
+			return nil, fmt.Errorf("invalid mode '%s': unknown file type '%c'", mode, mode[0])
+		}
+
+		mode = mode[1:]
+
+	default:
+		return nil, fmt.Errorf("invalid symbolic mode '%s': expected 9 or 10 characters, got %d", input, len(input))
+	}
+
+	specials := []Special{SetUID, SetGID, Sticky}
+	specialSymbols := "sst"
+
+	s := NewState()
+
+	for i, u := range users {
+		triplet := mode[i*3 : i*3+3]
+
+		for j, a := range accesses {
+			c := triplet[j]
+
+			switch {
+			case c == '-':
+				s.Users[u][a] = false
+
+			case c == a[0]:
+				s.Users[u][a] = true
+
+			case a == ExecuteAccess && c == 'X':
+				s.Users[u][a] = false
+				s.Users[u][ConditionalExecuteAccess] = true
+
+			case a == ExecuteAccess && c == specialSymbols[i]:
+				s.Users[u][a] = true
+				s.Special[specials[i]] = true
+
+			case a == ExecuteAccess && c == strings.ToUpper(specialSymbols[i : i+1])[0]:
+				s.Users[u][a] = false
+				s.Special[specials[i]] = true
+
+			default:
+				return nil, fmt.Errorf("invalid symbolic mode '%s': unexpected '%c' in %s permissions", input, c, u)
+			}
+		}
+	}
+
+	return s, nil
+}
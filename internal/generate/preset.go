@@ -0,0 +1,31 @@
+package generate
+
+import "fmt"
+
+// Preset is a named mode for a common use case. Mode is parsed with ParseMode
+// and PathType is either "File" or "Directory"
+type Preset struct {
+	Name     string
+	Mode     string
+	PathType string
+}
+
+// Presets are the built-in presets, in the order they're listed in the tui
+var Presets = []Preset{
+	{Name: "Private key", Mode: "600", PathType: "File"},
+	{Name: "Regular file", Mode: "644", PathType: "File"},
+	{Name: "Executable script", Mode: "755", PathType: "File"},
+	{Name: "Private directory", Mode: "700", PathType: "Directory"},
+	{Name: "Web root directory", Mode: "755", PathType: "Directory"},
+	{Name: "Shared group directory", Mode: "2775", PathType: "Directory"},
+}
+
+// State returns a new state with the preset's mode
+func (p Preset) State() (*State, error) {
+	s, err := ParseMode(p.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("preset '%s': %w", p.Name, err)
+	}
+
+	return s, nil
+}
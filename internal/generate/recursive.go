@@ -0,0 +1,152 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+)
+
+var accessNames = map[Access]string{
+	ReadAccess:    "read",
+	WriteAccess:   "write",
+	ExecuteAccess: "execute",
+}
+
+// Clone returns a deep copy of the state
+func (s *State) Clone() *State {
+	c := NewState()
+
+	for u, accesses := range s.Users {
+		for a, v := range accesses {
+			c.Users[u][a] = v
+		}
+	}
+
+	for u, changes := range s.Changes {
+		for a, op := range changes {
+			c.Changes[u][a] = op
+		}
+	}
+
+	for special, v := range s.Special {
+		c.Special[special] = v
+	}
+
+	for special, op := range s.SpecialChanges {
+		c.SpecialChanges[special] = op
+	}
+
+	c.Command = s.Command
+	c.PWD = s.PWD
+
+	return c
+}
+
+// Expression renders the state as an absolute symbolic expression accepted by
+// chmod, e.g "u=rwx,go=rx"
+func (s *State) Expression() string {
+	// a state always agrees with itself so this can't fail
+	expr, _ := RecursiveExpression(s, s)
+
+	return expr
+}
+
+// RecursiveExpression renders files and dirs as one symbolic expression for
+// chmod -R, using the conditional execute bit (X) for classes where only
+// directories are executable, e.g "u=rwX,go=rX". It fails when the two states
+// differ in a way X cannot express
+func RecursiveExpression(files, dirs *State) (string, error) {
+	perms := map[User]string{}
+
+	for _, u := range users {
+		p := strings.Builder{}
+
+		for _, a := range []Access{ReadAccess, WriteAccess} {
+			if files.Users[u][a] != dirs.Users[u][a] {
+				return "", fmt.Errorf("%s permission for %s differs between files and directories", accessNames[a], u)
+			}
+
+			if files.Users[u][a] {
+				p.WriteString(string(a))
+			}
+		}
+
+		fileExec := files.Users[u][ExecuteAccess]
+		dirExec := dirs.Users[u][ExecuteAccess] || dirs.Users[u][ConditionalExecuteAccess]
+
+		switch {
+		case fileExec && dirExec:
+			p.WriteString("x")
+
+		case dirExec:
+			p.WriteString("X")
+
+		case fileExec:
+			return "", fmt.Errorf("execute permission for %s is set on files but not directories, which X cannot express", u)
+		}
+
+		if special, ok := classSpecial[u]; ok {
+			if files.Special[special] != dirs.Special[special] {
+				return "", fmt.Errorf("%s differs between files and directories", special)
+			}
+
+			if files.Special[special] {
+				p.WriteString("s")
+			}
+		}
+
+		perms[u] = p.String()
+	}
+
+	if files.Special[Sticky] != dirs.Special[Sticky] {
+		return "", fmt.Errorf("%s differs between files and directories", Sticky)
+	}
+
+	clauses := []string{}
+	emitted := map[User]bool{}
+
+	for _, u := range users {
+		if emitted[u] {
+			continue
+		}
+
+		who := strings.Builder{}
+
+		for _, v := range users {
+			if perms[v] == perms[u] {
+				who.WriteString(userSymbols[v])
+				emitted[v] = true
+			}
+		}
+
+		if who.Len() == len(users) {
+			who.Reset()
+			who.WriteString("a")
+		}
+
+		clauses = append(clauses, who.String()+"="+perms[u])
+	}
+
+	if files.Special[Sticky] {
+		clauses = append(clauses, "+t")
+	}
+
+	return strings.Join(clauses, ","), nil
+}
+
+// FindCommands returns a pair of find commands that chmod the directories
+// under target to dirMode and the regular files to fileMode. An empty mode,
+// such as a relative mode without changes, has no command
+func FindCommands(target, flag, dirMode, fileMode string) []string {
+	chmod := strings.Join(strings.Fields(fmt.Sprintf("chmod %s", flag)), " ")
+	commands := []string{}
+
+	if dirMode != "" {
+		commands = append(commands, fmt.Sprintf("find %s -type d -exec %s %s {} +", target, chmod, dirMode))
+	}
+
+	if fileMode != "" {
+		commands = append(commands, fmt.Sprintf("find %s -type f -exec %s %s {} +", target, chmod, fileMode))
+	}
+
+	return commands
+}
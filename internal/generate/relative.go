@@ -0,0 +1,233 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Operation is a relative change applied to a permission bit
+type Operation string
+
+const (
+	Unchanged = Operation("")
+	Add       = Operation("+")
+	Remove    = Operation("-")
+)
+
+var userSymbols = map[User]string{
+	Owner: "u",
+	Group: "g",
+	Other: "o",
+}
+
+// classSpecial maps each class to the special bit expressed through it in symbolic mode
+var classSpecial = map[User]Special{
+	Owner: SetUID,
+	Group: SetGID,
+}
+
+// buildRelative renders the relative changes as a minimal list of who/op/perm
+// clauses, e.g "u+x,g+w,o-rwx". Bits changed the same way for the same classes
+// are merged into one clause ("go-w", "a+x"). The sticky bit is emitted on its
+// own ("+t") since BSD chmod ignores it when combined with "o"
+func (s *State) buildRelative() string {
+	clauses := []string{}
+
+	for _, op := range []Operation{Add, Remove} {
+		// group the bits by the classes they apply to, keyed by a u/g/o bitmask
+		groups := map[int]string{}
+
+		for _, perm := range []string{"r", "w", "x", "X", "s"} {
+			mask := 0
+
+			for i, u := range users {
+				if s.userChange(u, perm) == op {
+					mask |= 4 >> i
+				}
+			}
+
+			if mask != 0 {
+				groups[mask] += perm
+			}
+		}
+
+		for mask := 7; mask > 0; mask-- {
+			if groups[mask] == "" {
+				continue
+			}
+
+			who := strings.Builder{}
+
+			for i, u := range users {
+				if mask&(4>>i) != 0 {
+					who.WriteString(userSymbols[u])
+				}
+			}
+
+			if mask == 7 {
+				who.Reset()
+				who.WriteString("a")
+			}
+
+			clauses = append(clauses, who.String()+string(op)+groups[mask])
+		}
+	}
+
+	if op := s.SpecialChanges[Sticky]; op != Unchanged {
+		clauses = append(clauses, string(op)+"t")
+	}
+
+	return strings.Join(clauses, ",")
+}
+
+// userChange returns the change made to a perm symbol (r, w, x, X or s) for a class
+func (s *State) userChange(u User, perm string) Operation {
+	if perm != "s" {
+		return s.Changes[u][Access(perm)]
+	}
+
+	special, ok := classSpecial[u]
+	if !ok {
+		return Unchanged
+	}
+
+	return s.SpecialChanges[special]
+}
+
+// a lone - needs a class before it, -rwxrwxrwx is an ls -l mode
+var relativePattern = regexp.MustCompile(`^[ugoa]+-[rwxXst]*$`)
+
+// relativeAccesses are the accesses a relative clause can change
+var relativeAccesses = []Access{ReadAccess, WriteAccess, ExecuteAccess, ConditionalExecuteAccess}
+
+// IsRelative reports whether a mode looks like a list of who/op/perm clauses
+// (u+x,go-w) rather than an absolute mode
+func IsRelative(mode string) bool {
+	return strings.ContainsAny(mode, "+=,") || relativePattern.MatchString(mode)
+}
+
+// ParseRelative parses a comma separated list of who/op/perm clauses, e.g
+// "u+x,go-w" or "u=rw,go=", into the Changes of a State. Clauses are applied in
+// order so later clauses override earlier ones
+func ParseRelative(expr string) (*State, error) {
+	expr = strings.TrimSpace(expr)
+
+	if expr == "" {
+		return nil, errors.New("mode cannot be empty")
+	}
+
+	s := NewState()
+
+	for _, clause := range strings.Split(expr, ",") {
+		if err := s.applyClause(clause); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+func (s *State) applyClause(clause string) error {
+	i := strings.IndexAny(clause, "+-=")
+	if i < 0 {
+		return fmt.Errorf("invalid clause '%s': missing operator (+, - or =)", clause)
+	}
+
+	classes := []User{}
+
+	for _, c := range clause[:i] {
+		switch c {
+		case 'u':
+			classes = append(classes, Owner)
+		case 'g':
+			classes = append(classes, Group)
+		case 'o':
+			classes = append(classes, Other)
+		case 'a':
+			classes = append(classes, users...)
+		default:
+			return fmt.Errorf("invalid clause '%s': unknown class '%c'", clause, c)
+		}
+	}
+
+	if len(classes) == 0 {
+		classes = users
+	}
+
+	// a clause may hold several op/perm pairs, e.g "u+r-x"
+	rest := clause[i:]
+
+	for len(rest) > 0 {
+		op := rest[0]
+		rest = rest[1:]
+
+		j := strings.IndexAny(rest, "+-=")
+		if j < 0 {
+			j = len(rest)
+		}
+
+		perms := rest[:j]
+		rest = rest[j:]
+
+		for _, c := range perms {
+			if !strings.ContainsRune("rwxXst", c) {
+				return fmt.Errorf("invalid clause '%s': unsupported permission '%c'", clause, c)
+			}
+		}
+
+		for _, u := range classes {
+			s.applyPerms(u, op, perms)
+		}
+	}
+
+	return nil
+}
+
+// applyPerms records the change an operator makes to the given perms of a class.
+// "=" adds the listed bits and removes the remaining rwx (and s) bits. X is
+// only recorded when listed
+func (s *State) applyPerms(u User, op byte, perms string) {
+	change := func(listed bool) Operation {
+		switch {
+		case op == '+' && listed:
+			return Add
+		case op == '-' && listed:
+			return Remove
+		case op == '=' && listed:
+			return Add
+		case op == '=':
+			return Remove
+		}
+
+		return Unchanged
+	}
+
+	for _, a := range relativeAccesses {
+		listed := strings.Contains(perms, string(a))
+
+		if a == ConditionalExecuteAccess && !listed {
+			continue
+		}
+
+		// "=X" must not remove execute from the paths X applies to
+		if a == ExecuteAccess && op == '=' && strings.Contains(perms, "X") {
+			continue
+		}
+
+		if op := change(listed); op != Unchanged {
+			s.Changes[u][a] = op
+		}
+	}
+
+	if special, ok := classSpecial[u]; ok {
+		if op := change(strings.Contains(perms, "s")); op != Unchanged {
+			s.SpecialChanges[special] = op
+		}
+	}
+
+	if strings.Contains(perms, "t") {
+		s.SpecialChanges[Sticky] = change(true)
+	}
+}
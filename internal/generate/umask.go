@@ -0,0 +1,70 @@
+package generate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+const (
+	// FileBase is the mode new files are requested with before the umask applies
+	FileBase = fs.FileMode(0666)
+	// DirectoryBase is the mode new directories are requested with before the umask applies
+	DirectoryBase = fs.FileMode(0777)
+)
+
+// Umask returns the umask that gives newly created paths of the given type
+// ("File" or "Directory") the state's mode
+func (s *State) Umask(pathType string) (fs.FileMode, error) {
+	for special := range specialBits {
+		if s.Special[special] {
+			return 0, fmt.Errorf("no umask can set %s, it has to be applied with chmod", special)
+		}
+	}
+
+	base := FileBase
+	mode := s.FileMode()
+
+	if pathType == "Directory" {
+		base = DirectoryBase
+
+		// conditional execute always applies to directories
+		for _, u := range users {
+			if s.Users[u][ConditionalExecuteAccess] {
+				mode |= accessBits[ExecuteAccess] << userShift[u]
+			}
+		}
+	}
+
+	if mode&^base != 0 {
+		return 0, errors.New("no umask gives files an execute bit, it has to be applied with chmod")
+	}
+
+	return base &^ mode, nil
+}
+
+// ParseUmask parses a three or four digit octal umask such as 022 or 0027
+func ParseUmask(umask string) (fs.FileMode, error) {
+	if !isNumeric(umask) {
+		return 0, fmt.Errorf("invalid umask '%s': only octal digits are allowed", umask)
+	}
+
+	s, err := parseOctal(umask)
+	if err != nil {
+		return 0, fmt.Errorf("invalid umask '%s': %w", umask, err)
+	}
+
+	mode := s.FileMode()
+
+	if mode&^fs.ModePerm != 0 {
+		return 0, fmt.Errorf("invalid umask '%s': the leading digit must be 0", umask)
+	}
+
+	return mode, nil
+}
+
+// DefaultModes returns the states newly created files and directories get with
+// the given umask
+func DefaultModes(umask fs.FileMode) (*State, *State) {
+	return NewStateFromMode(FileBase &^ umask), NewStateFromMode(DirectoryBase &^ umask)
+}
@@ -0,0 +1,180 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/config"
+)
+
+// FileName is the name of the history file in the user data directory
+const FileName = "history.jsonl"
+
+// Actions an entry can be recorded for
+const (
+	Copy  = "copy"
+	Apply = "apply"
+	Print = "print"
+)
+
+// Entry is a command that was copied, applied or printed. Dir is the working
+// directory the targets are relative to
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Command string    `json:"command"`
+	Targets []string  `json:"targets,omitempty"`
+	Dir     string    `json:"dir,omitempty"`
+}
+
+// NewEntry returns an entry for a command run now in the working directory
+func NewEntry(action, command string, targets []string) Entry {
+	dir, _ := os.Getwd()
+
+	return Entry{
+		Time:    time.Now(),
+		Action:  action,
+		Command: command,
+		Targets: targets,
+		Dir:     dir,
+	}
+}
+
+// OneLine returns the command on a single line, recursive commands are a find
+// pair on separate lines which are joined with &&
+func (e Entry) OneLine() string {
+	return strings.ReplaceAll(e.Command, "\n", " && ")
+}
+
+// UserHistoryPath returns the path of the history file in the user data
+// directory, which is $XDG_DATA_HOME or ~/.local/share on unix systems
+func UserHistoryPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, config.AppName, FileName), nil
+}
+
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+
+	// the config directory is the closest match on windows and macos
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return os.UserConfigDir()
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// Append adds the entry to the end of the history file at path, creating it
+// and its directory when missing
+func Append(path string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load reads the entries of the history file at path, most recent first. A
+// missing file has no entries and malformed lines are skipped
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Entry{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		entry := Entry{}
+
+		// a line cut short by an interrupted write shouldn't hide the rest
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
+
+// Clear removes the history file at path
+func Clear(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Search returns the entries whose command, targets or directory contain the
+// query, compared case-insensitively
+func Search(entries []Entry, query string) []Entry {
+	query = strings.ToLower(query)
+	found := []Entry{}
+
+	for _, v := range entries {
+		fields := append([]string{v.Command, v.Dir}, v.Targets...)
+
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), query) {
+				found = append(found, v)
+				break
+			}
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,85 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendLoad(t *testing.T) {
+	is := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "chmod-cli", FileName)
+
+	entries, err := Load(path)
+	is.NoError(err)
+	is.Empty(entries)
+
+	first := Entry{Time: time.Unix(1700000000, 0).UTC(), Action: Copy, Command: "chmod 644 app.conf", Targets: []string{"app.conf"}, Dir: "/srv"}
+	second := Entry{Time: time.Unix(1700000060, 0).UTC(), Action: Apply, Command: "chmod 755 bin", Dir: "/srv"}
+
+	is.NoError(Append(path, first))
+	is.NoError(Append(path, second))
+
+	entries, err = Load(path)
+	is.NoError(err)
+	is.Equal([]Entry{second, first}, entries)
+
+	stat, err := os.Stat(path)
+	is.NoError(err)
+
+	if mode := stat.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected mode to be '0600', instead got '%04o'", mode)
+	}
+
+	is.NoError(os.WriteFile(path, []byte("{\"command\":\"chmod 644\"}\n{broken\n{\"command\":\"chmod 755\"}\n"), 0600))
+
+	entries, err = Load(path)
+	is.NoError(err)
+	is.Equal([]Entry{{Command: "chmod 755"}, {Command: "chmod 644"}}, entries)
+
+	is.NoError(Clear(path))
+	is.NoError(Clear(path))
+
+	entries, err = Load(path)
+	is.NoError(err)
+	is.Empty(entries)
+}
+
+func TestSearch(t *testing.T) {
+	is := require.New(t)
+
+	entries := []Entry{
+		{Command: "chmod 600 id_rsa", Targets: []string{"id_rsa"}, Dir: "/home/ada/.ssh"},
+		{Command: "chmod 755 deploy.sh", Targets: []string{"deploy.sh"}, Dir: "/srv/app"},
+		{Command: "chmod g+w shared", Dir: "/srv/team"},
+	}
+
+	is.Len(Search(entries, "CHMOD"), 3)
+	is.Equal(entries[:1], Search(entries, "rsa"))
+	is.Equal(entries[1:], Search(entries, "/srv"))
+	is.Empty(Search(entries, "missing"))
+}
+
+func TestOneLine(t *testing.T) {
+	is := require.New(t)
+
+	entry := Entry{Command: "find . -type d -exec chmod 755 {} +\nfind . -type f -exec chmod 644 {} +"}
+
+	is.Equal("find . -type d -exec chmod 755 {} + && find . -type f -exec chmod 644 {} +", entry.OneLine())
+	is.Equal("chmod 644", Entry{Command: "chmod 644"}.OneLine())
+}
+
+func TestUserHistoryPath(t *testing.T) {
+	is := require.New(t)
+
+	defer os.Setenv("XDG_DATA_HOME", os.Getenv("XDG_DATA_HOME"))
+	is.NoError(os.Setenv("XDG_DATA_HOME", "/tmp/data"))
+
+	path, err := UserHistoryPath()
+	is.NoError(err)
+	is.Equal(filepath.Join("/tmp/data", "chmod-cli", FileName), path)
+}
@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"io/fs"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+)
+
+type Severity int
+
+const (
+	Low Severity = iota
+	Medium
+	High
+)
+
+func (s Severity) String() string {
+	return [...]string{"low", "medium", "high"}[s]
+}
+
+// MarshalText encodes the severity by name, e.g in json reports
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Finding is a single rule violation
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Message  string
+}
+
+// Rule checks a resolved mode, dir reports whether the mode is for a directory.
+// Fix returns the mode that resolves the violation
+type Rule struct {
+	Name     string
+	Severity Severity
+	Check    func(mode fs.FileMode, dir bool) (string, bool)
+	Fix      func(mode fs.FileMode, dir bool) fs.FileMode
+}
+
+const (
+	ownerExec  = fs.FileMode(0100)
+	groupWrite = fs.FileMode(0020)
+	groupExec  = fs.FileMode(0010)
+	otherWrite = fs.FileMode(0002)
+)
+
+// Rules are evaluated in order by Lint
+var Rules = []Rule{
+	{
+		Name:     "world-writable",
+		Severity: High,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if mode&otherWrite == 0 {
+				return "", false
+			}
+
+			if !dir {
+				return "anyone can modify this file", true
+			}
+
+			if mode&fs.ModeSticky == 0 {
+				return "anyone can delete or rename entries, set the sticky bit to restrict this", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			return mode &^ otherWrite
+		},
+	},
+	{
+		Name:     "writable-setuid",
+		Severity: High,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if dir || mode&(fs.ModeSetuid|fs.ModeSetgid) == 0 {
+				return "", false
+			}
+
+			if mode&(groupWrite|otherWrite) != 0 {
+				return "a setuid/setgid file writable by others can be replaced with arbitrary code", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			return mode &^ (groupWrite | otherWrite)
+		},
+	},
+	{
+		Name:     "setuid-directory",
+		Severity: Medium,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if dir && mode&fs.ModeSetuid != 0 {
+				return "setuid has no effect on directories on most systems", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			return mode &^ fs.ModeSetuid
+		},
+	},
+	{
+		Name:     "shared-directory-without-setgid",
+		Severity: Low,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			// world-writable directories are shared with everyone, not a group
+			if !dir || mode&otherWrite != 0 {
+				return "", false
+			}
+
+			if mode&groupWrite != 0 && mode&fs.ModeSetgid == 0 {
+				return "new entries in a group-writable directory won't inherit its group without setgid", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			return mode | fs.ModeSetgid
+		},
+	},
+	{
+		Name:     "executable-not-readable",
+		Severity: Low,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if dir {
+				return "", false
+			}
+
+			for shift := 6; shift >= 0; shift -= 3 {
+				class := mode >> uint(shift) & 07
+
+				if class&01 != 0 && class&04 == 0 {
+					return "executable but not readable, scripts need read permission to run", true
+				}
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			for shift := 6; shift >= 0; shift -= 3 {
+				if mode>>uint(shift)&01 != 0 {
+					mode |= 04 << uint(shift)
+				}
+			}
+
+			return mode
+		},
+	},
+	{
+		Name:     "special-without-execute",
+		Severity: Low,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if dir {
+				return "", false
+			}
+
+			if mode&fs.ModeSetuid != 0 && mode&ownerExec == 0 {
+				return "setuid has no effect without owner execute", true
+			}
+
+			if mode&fs.ModeSetgid != 0 && mode&groupExec == 0 {
+				return "setgid without group execute enables mandatory locking on some systems instead", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			if mode&ownerExec == 0 {
+				mode &^= fs.ModeSetuid
+			}
+
+			if mode&groupExec == 0 {
+				mode &^= fs.ModeSetgid
+			}
+
+			return mode
+		},
+	},
+	{
+		Name:     "sticky-file",
+		Severity: Low,
+		Check: func(mode fs.FileMode, dir bool) (string, bool) {
+			if !dir && mode&fs.ModeSticky != 0 {
+				return "the sticky bit is ignored on files by modern systems", true
+			}
+
+			return "", false
+		},
+		Fix: func(mode fs.FileMode, dir bool) fs.FileMode {
+			return mode &^ fs.ModeSticky
+		},
+	},
+}
+
+// Lint evaluates an absolute state for the given path type ("File" or
+// "Directory") against Rules
+func Lint(s *generate.State, pathType string) []Finding {
+	var current fs.FileMode
+
+	if pathType == "Directory" {
+		current = fs.ModeDir
+	}
+
+	return LintMode(s.ResolveMode("Symbolic", current), pathType == "Directory")
+}
+
+// LintMode evaluates a resolved mode against Rules
+func LintMode(mode fs.FileMode, dir bool) []Finding {
+	findings := []Finding{}
+
+	for _, r := range Rules {
+		if message, ok := r.Check(mode, dir); ok {
+			findings = append(findings, Finding{
+				Rule:     r.Name,
+				Severity: r.Severity,
+				Message:  message,
+			})
+		}
+	}
+
+	return findings
+}
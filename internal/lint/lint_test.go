@@ -0,0 +1,88 @@
+package lint
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/stretchr/testify/require"
+)
+
+func rules(findings []Finding) []string {
+	names := []string{}
+
+	for _, f := range findings {
+		names = append(names, f.Rule)
+	}
+
+	return names
+}
+
+func TestLint(t *testing.T) {
+	is := require.New(t)
+
+	modes := []struct {
+		mode     string
+		pathType string
+		expected []string
+	}{
+		{"644", "File", []string{}},
+		{"755", "Directory", []string{}},
+		{"1777", "Directory", []string{}},
+		{"777", "Directory", []string{"world-writable"}},
+		{"775", "Directory", []string{"shared-directory-without-setgid"}},
+		{"666", "File", []string{"world-writable"}},
+		{"4775", "File", []string{"writable-setuid"}},
+		{"4755", "Directory", []string{"setuid-directory"}},
+		{"2775", "Directory", []string{}},
+		{"711", "File", []string{"executable-not-readable"}},
+		{"4644", "File", []string{"special-without-execute"}},
+		{"1644", "File", []string{"sticky-file"}},
+	}
+
+	for _, v := range modes {
+		s, err := generate.ParseMode(v.mode)
+		is.NoError(err)
+
+		is.Equal(v.expected, rules(Lint(s, v.pathType)), "%s %s", v.pathType, v.mode)
+	}
+}
+
+func TestLintConditionalExecute(t *testing.T) {
+	is := require.New(t)
+
+	s, err := generate.ParseMode("rwXrwXrwX")
+	is.NoError(err)
+
+	is.Equal([]string{"world-writable"}, rules(Lint(s, "Directory")))
+	is.Equal([]string{"world-writable"}, rules(Lint(s, "File")))
+}
+
+func TestFix(t *testing.T) {
+	is := require.New(t)
+
+	modes := []struct {
+		mode     fs.FileMode
+		dir      bool
+		expected fs.FileMode
+	}{
+		{0666, false, 0664},
+		{fs.ModeSetuid | 0775, false, fs.ModeSetuid | 0755},
+		{fs.ModeSetuid | 0755, true, 0755},
+		{0775, true, fs.ModeSetgid | 0775},
+		{0711, false, 0755},
+	}
+
+	for _, v := range modes {
+		fixed := v.mode
+
+		for _, r := range Rules {
+			if _, ok := r.Check(fixed, v.dir); ok {
+				fixed = r.Fix(fixed, v.dir)
+			}
+		}
+
+		is.Equal(v.expected, fixed, "%s", v.mode)
+		is.Empty(LintMode(fixed, v.dir))
+	}
+}
@@ -3,16 +3,21 @@ package ui
 import "github.com/charmbracelet/bubbles/key"
 
 type KeyMap struct {
-	Up      key.Binding
-	Down    key.Binding
-	Left    key.Binding
-	Right   key.Binding
-	TabUp   key.Binding
-	TabDown key.Binding
-	Select  key.Binding
-	Copy    key.Binding
-	Quit    key.Binding
-	Help    key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Left      key.Binding
+	Right     key.Binding
+	TabUp     key.Binding
+	TabDown   key.Binding
+	Select    key.Binding
+	Copy      key.Binding
+	Apply     key.Binding
+	Recursive key.Binding
+	Undo      key.Binding
+	Redo      key.Binding
+	Quit      key.Binding
+	Cancel    key.Binding
+	Help      key.Binding
 }
 
 func NewKeyMap() *KeyMap {
@@ -49,10 +54,32 @@ func NewKeyMap() *KeyMap {
 			key.WithKeys("ctrl+c"),
 			key.WithHelp("ctrl+c", "copy command"),
 		),
+		Apply: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "apply to targets"),
+		),
+		Recursive: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "toggle recursive"),
+		),
+		Undo: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "undo"),
+		),
+		Redo: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "redo"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q", "esc"),
 			key.WithHelp("q", "quit"),
 		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+			// only used in print mode
+			key.WithDisabled(),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "toggle help"),
@@ -67,7 +94,7 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.TabDown, k.TabUp, k.Select, k.Copy},
-		{k.Help, k.Quit},
+		{k.TabDown, k.TabUp, k.Select, k.Copy, k.Undo, k.Redo},
+		{k.Apply, k.Recursive, k.Help, k.Quit, k.Cancel},
 	}
 }
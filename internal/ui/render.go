@@ -2,10 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"io/fs"
 	"math"
+	"path/filepath"
 	"strings"
 
 	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/lint"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -22,15 +26,273 @@ func (m Model) renderHeader() string {
 	return h.String()
 }
 
+// footerCommand returns the command, or a hint while a relative mode has no
+// changes to build one from
+func (m Model) footerCommand() string {
+	if m.state.Command == "" && m.mode.selected == "Relative" {
+		return GetStyles().FooterUmask.Render("no changes yet, mark permissions to add or remove")
+	}
+
+	return m.state.Command
+}
+
 func (m Model) renderFooter() string {
 	styles := GetStyles()
 
 	footer := styles.Footer
-	footerContent := styles.FooterContent.Render(fmt.Sprintf("Command: %s", m.state.Command))
+
+	if m.err != nil {
+		return footer.Render(styles.FooterError.Render(fmt.Sprintf("Error: %s", m.err)))
+	}
+
+	if m.dirState != nil {
+		variant := styles.FooterContent.Render(fmt.Sprintf("chmod -R: %s", m.recursiveCommand))
+
+		if m.recursiveErr != nil {
+			variant = styles.FooterError.Render(fmt.Sprintf("chmod -R: %s", m.recursiveErr))
+		}
+
+		return footer.Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			styles.FooterContent.Render("Command:"),
+			styles.FooterContent.Render(m.footerCommand()),
+			"",
+			variant,
+		))
+	}
+
+	footerContent := styles.FooterContent.Render(fmt.Sprintf("Command: %s", m.footerCommand()))
+
+	// the ls style form of the mode is only shown, chmod can't parse it
+	if symbolic, err := m.state.BuildCommand("Symbolic"); err == nil && m.mode.selected == "Symbolic" {
+		fileType := "-"
+		if m.path.selected == "Directory" {
+			fileType = "d"
+		}
+
+		footerContent = lipgloss.JoinVertical(
+			lipgloss.Left,
+			footerContent,
+			styles.FooterUmask.Render(fmt.Sprintf("Mode: %s%s", fileType, symbolic)),
+		)
+	}
+
+	// the umask that would give new paths of the selected type this mode
+	if umask, err := m.state.Umask(m.path.selected); err == nil && m.mode.selected != "Relative" {
+		footerContent = lipgloss.JoinVertical(
+			lipgloss.Left,
+			footerContent,
+			styles.FooterUmask.Render(fmt.Sprintf("Umask: %04o", uint32(umask))),
+		)
+	}
 
 	return footer.Render(footerContent)
 }
 
+func (m Model) renderLint(findings []lint.Finding) string {
+	styles := GetStyles()
+
+	lines := []string{}
+
+	for _, f := range findings {
+		style := styles.LintLow
+
+		switch f.Severity {
+		case lint.High:
+			style = styles.LintHigh
+
+		case lint.Medium:
+			style = styles.LintMedium
+		}
+
+		lines = append(lines, style.Render(fmt.Sprintf("%s %s: %s", warning, f.Rule, f.Message)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m Model) renderResults() string {
+	styles := GetStyles()
+
+	r := strings.Builder{}
+
+	r.WriteString(m.renderHeader())
+	r.WriteString("\n")
+	r.WriteString(styles.ResultsHeader.Render("Apply Results"))
+	r.WriteString("\n")
+
+	for _, v := range m.results {
+		if v.Err != nil {
+			r.WriteString(styles.ResultsFailure.Render(fmt.Sprintf("✗ %s: %s", v.Path, v.Err)))
+		} else {
+			r.WriteString(styles.ResultsSuccess.Render(fmt.Sprintf("✓ %s → %s", v.Path, v.Mode)))
+		}
+
+		r.WriteString("\n")
+	}
+
+	r.WriteString("\n")
+	r.WriteString(styles.ResultsHint.Render("press any key to exit"))
+	r.WriteString("\n")
+
+	return r.String()
+}
+
+func (m Model) renderDiff() string {
+	styles := GetStyles()
+
+	before := m.sourceMode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+	after := m.activeState().ResolveMode(m.mode.selected, m.sourceMode)
+
+	source := m.source
+	if source == "" {
+		source = "working directory"
+	}
+
+	beforeLine, afterLine := diffModes(before, after)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		styles.DiffHeader.Render("Diff"),
+		styles.DiffSource.Render(source),
+		fmt.Sprintf("Before: %s (%s)", beforeLine, octalMode(before)),
+		fmt.Sprintf("After:  %s (%s)", afterLine, octalMode(after)),
+	)
+}
+
+// diffModes renders the symbolic form of both modes, highlighting the bits
+// removed from before in red and the bits added in after in green
+func diffModes(before, after fs.FileMode) (string, string) {
+	styles := GetStyles()
+
+	beforeSymbolic := symbolicMode(before)
+	afterSymbolic := symbolicMode(after)
+
+	b := strings.Builder{}
+	a := strings.Builder{}
+
+	for i := 0; i < 9; i++ {
+		mask := fs.FileMode(1) << (8 - i)
+
+		switch i {
+		case 2:
+			mask |= fs.ModeSetuid
+		case 5:
+			mask |= fs.ModeSetgid
+		case 8:
+			mask |= fs.ModeSticky
+		}
+
+		removed := before & mask &^ after
+		added := after & mask &^ before
+
+		beforeChar := string(beforeSymbolic[i])
+		afterChar := string(afterSymbolic[i])
+
+		switch {
+		case added != 0 && removed != 0:
+			b.WriteString(styles.DiffChanged.Render(beforeChar))
+			a.WriteString(styles.DiffChanged.Render(afterChar))
+
+		case removed != 0:
+			b.WriteString(styles.DiffRemoved.Render(beforeChar))
+			a.WriteString(styles.DiffRemoved.Render(afterChar))
+
+		case added != 0:
+			b.WriteString(beforeChar)
+			a.WriteString(styles.DiffAdded.Render(afterChar))
+
+		default:
+			b.WriteString(beforeChar)
+			a.WriteString(afterChar)
+		}
+	}
+
+	return b.String(), a.String()
+}
+
+// renderPreview lists the preview entries with their current mode and the
+// mode they would have once the command is applied
+func (m Model) renderPreview() string {
+	styles := GetStyles()
+
+	source := "targets"
+	if len(m.files.selected) == 0 {
+		source = "working directory"
+	}
+
+	lines := []string{
+		styles.PreviewHeader.Render("Preview"),
+		styles.DiffSource.Render(source),
+	}
+
+	if len(m.preview) == 0 {
+		lines = append(lines, styles.PreviewMore.Render("nothing to preview"))
+	}
+
+	for _, v := range m.preview {
+		if v.Err != nil {
+			lines = append(lines, styles.PreviewError.Render(fmt.Sprintf("✗ %s", v.Err)))
+			continue
+		}
+
+		before := v.Mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+		beforeLine, afterLine := diffModes(before, m.previewMode(v.Mode))
+		fileType := lsType(v.Mode)
+
+		lines = append(lines, fmt.Sprintf("%s%s → %s%s  %s", fileType, beforeLine, fileType, afterLine, v.Path))
+	}
+
+	if more := m.previewTotal - len(m.preview); more > 0 {
+		lines = append(lines, styles.PreviewMore.Render(fmt.Sprintf("… and %d more", more)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// lsType returns the file type character ls shows in front of the permissions
+func lsType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "d"
+
+	case mode&fs.ModeSymlink != 0:
+		return "l"
+
+	case mode&fs.ModeNamedPipe != 0:
+		return "p"
+
+	case mode&fs.ModeSocket != 0:
+		return "s"
+
+	case mode&fs.ModeCharDevice != 0:
+		return "c"
+
+	case mode&fs.ModeDevice != 0:
+		return "b"
+	}
+
+	return "-"
+}
+
+func symbolicMode(mode fs.FileMode) string {
+	symbolic, err := generate.NewStateFromMode(mode).BuildCommand("Symbolic")
+	if err != nil {
+		return strings.Repeat("?", 9)
+	}
+
+	return symbolic
+}
+
+func octalMode(mode fs.FileMode) string {
+	octal, err := generate.NewStateFromMode(mode).BuildCommand("Octal")
+	if err != nil {
+		return "???"
+	}
+
+	return octal
+}
+
 func (o *Options) renderOptions() string {
 	styles := GetStyles()
 
@@ -56,6 +318,132 @@ func (o *Options) renderOptions() string {
 	return styles.OptionsContainer(options)
 }
 
+// renderFiles lists a window of the entries of the directory opened in the
+// file picker, marking the selected ones
+func (f *Files) renderFiles() string {
+	styles := GetStyles()
+
+	lines := []string{
+		styles.FilesHeader.Render("Files"),
+		styles.DiffSource.Render(filepath.ToSlash(f.dir) + "/"),
+	}
+
+	if len(f.entries) == 0 {
+		lines = append(lines, styles.FilesMore.Render("empty directory"))
+	}
+
+	for i := f.offset; i < len(f.entries) && i < f.offset+FilesLimit; i++ {
+		v := f.entries[i]
+		focused := f.cursor == i
+		active := f.findSelected(filepath.Join(f.dir, v.Name)) >= 0
+
+		name := v.Name
+		if v.IsDir {
+			name += "/"
+		}
+
+		if focused && active {
+			lines = append(lines, styles.FilesActiveItem.Render(fmt.Sprintf("%s %s", checkActive, name)))
+		} else if focused {
+			lines = append(lines, styles.FilesActiveItem.Render(fmt.Sprintf("%s %s", checkInactive, name)))
+		} else if active {
+			lines = append(lines, fmt.Sprintf("%s %s", styles.FilesActiveItem.Render(checkActive), name))
+		} else {
+			lines = append(lines, styles.FilesItem.Render(fmt.Sprintf("%s %s", checkInactive, name)))
+		}
+	}
+
+	if more := len(f.entries) - f.offset - FilesLimit; more > 0 {
+		lines = append(lines, styles.FilesMore.Render(fmt.Sprintf("… and %d more", more)))
+	}
+
+	lines = append(lines, styles.FilesMore.Render(fmt.Sprintf("%d selected", len(f.selected))))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// renderHistory lists a window of the history entries, most recent first
+func (h *History) renderHistory() string {
+	styles := GetStyles()
+
+	lines := []string{styles.HistoryHeader.Render("History")}
+
+	if len(h.entries) == 0 {
+		lines = append(lines, styles.HistoryTime.Render("no commands yet"))
+	}
+
+	for i := h.offset; i < len(h.entries) && i < h.offset+HistoryLimit; i++ {
+		v := h.entries[i]
+
+		command := truncate(v.OneLine(), 38)
+		when := styles.HistoryTime.Render(v.Time.Local().Format("Jan 02 15:04"))
+
+		if h.cursor == i {
+			lines = append(lines, fmt.Sprintf("%s %s", when, styles.HistoryActiveItem.Render(command)))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %s", when, styles.HistoryItem.Render(command)))
+		}
+	}
+
+	if more := len(h.entries) - h.offset - HistoryLimit; more > 0 {
+		lines = append(lines, styles.HistoryTime.Render(fmt.Sprintf("… and %d more", more)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// truncate shortens s to at most width characters, ending it with … when cut
+func truncate(s string, width int) string {
+	runes := []rune(s)
+
+	if len(runes) <= width {
+		return s
+	}
+
+	return string(runes[:width-1]) + "…"
+}
+
+// renderPresets lists the presets, marking the ones that match the mode and
+// path type being built
+func (p *Presets) renderPresets(mode fs.FileMode, pathType string) string {
+	styles := GetStyles()
+
+	presets := strings.Builder{}
+	width := 0
+
+	for _, v := range p.values {
+		if len(v.Name) > width {
+			width = len(v.Name)
+		}
+	}
+
+	for i, v := range p.values {
+		focused := p.cursor == i
+		active := false
+
+		if state, err := v.State(); err == nil {
+			active = v.PathType == pathType && state.FileMode() == mode
+		}
+
+		name := fmt.Sprintf("%-*s", width, v.Name)
+		octal := styles.PresetsMode.Render(v.Mode)
+
+		if focused && active {
+			presets.WriteString(styles.PresetsActiveItem.Render(fmt.Sprintf("%s %s", radioActive, name)))
+		} else if focused {
+			presets.WriteString(styles.PresetsActiveItem.Render(fmt.Sprintf("%s %s", radioInactive, name)))
+		} else if active {
+			presets.WriteString(fmt.Sprintf("%s %s", styles.PresetsActiveItem.Render(radioActive), name))
+		} else {
+			presets.WriteString(styles.PresetsItem.Render(fmt.Sprintf("%s %s", radioInactive, name)))
+		}
+
+		presets.WriteString(fmt.Sprintf(" %s\n", octal))
+	}
+
+	return styles.PresetsContainer(presets)
+}
+
 func (c *CommandMode) renderCommandMode() string {
 	styles := GetStyles()
 
@@ -79,6 +467,23 @@ func (c *CommandMode) renderCommandMode() string {
 	return styles.CommandModeContainer(modes...)
 }
 
+// renderModeInput shows the mode field, with the parser error below it while
+// the typed mode is invalid
+func (i *ModeInput) renderModeInput() string {
+	styles := GetStyles()
+
+	lines := []string{
+		styles.ModeInputHeader.Render("Mode"),
+		i.field.View(),
+	}
+
+	if i.err != nil {
+		lines = append(lines, styles.ModeInputError.Render(i.err.Error()))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 func (p *PathType) renderPathType() string {
 	styles := GetStyles()
 
@@ -99,15 +504,23 @@ func (p *PathType) renderPathType() string {
 		}
 	}
 
-	return styles.PathTypeContainer(paths...)
+	title := "Path Type"
+
+	if p.recursive {
+		title = "Path Type · Recursive"
+	}
+
+	return styles.PathTypeContainer(title, paths...)
 }
 
 func (p *Permissions) renderPermissions() string {
 	styles := GetStyles()
 
-	var ownerBlock, groupBlock, otherBlock []string
+	var ownerBlock, groupBlock, otherBlock, specialBlock []string
+
+	for i, v := range p.values {
+		label := getPermissionLabel(v)
 
-	for _, v := range p.values {
 		var currBlock PermissionsBlock
 		if p.cursor >= 0 {
 			currBlock = p.blocks[p.cursor]
@@ -115,69 +528,91 @@ func (p *Permissions) renderPermissions() string {
 
 		{
 			if len(ownerBlock) < 1 {
-				ownerBlock = append(ownerBlock, styles.PermissionsBlockItem.Copy().Foreground(lipgloss.Color(ColorYellow)).Render("[Owner]"))
+				ownerBlock = append(ownerBlock, styles.PermissionsBlockTitle.Render("[Owner]"))
 				ownerBlock = append(ownerBlock, styles.PermissionsBlockItem.Render(strings.Repeat("-", 7)))
 			}
 
 			focused := p.cursor == 0 && p.values[currBlock.cursor] == v
-			active := common.IncludesString(p.blocks[0].selected, v)
+			active, check := p.itemState(0, v)
 
 			if focused && active {
-				ownerBlock = append(ownerBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkActive, v)))
+				ownerBlock = append(ownerBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", check, label)))
 			} else if active {
-				ownerBlock = append(ownerBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(checkActive), v))
+				ownerBlock = append(ownerBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(check), label))
 			} else if focused {
-				ownerBlock = append(ownerBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				ownerBlock = append(ownerBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
 			} else {
-				ownerBlock = append(ownerBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				ownerBlock = append(ownerBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
 			}
 		}
 
 		{
 			if len(groupBlock) < 1 {
-				groupBlock = append(groupBlock, styles.PermissionsBlockItem.Copy().Foreground(lipgloss.Color(ColorYellow)).Render("[Group]"))
+				groupBlock = append(groupBlock, styles.PermissionsBlockTitle.Render("[Group]"))
 				groupBlock = append(groupBlock, styles.PermissionsBlockItem.Render(strings.Repeat("-", 7)))
 			}
 
 			focused := p.cursor == 1 && p.values[currBlock.cursor] == v
-			active := common.IncludesString(p.blocks[1].selected, v)
+			active, check := p.itemState(1, v)
 
 			if focused && active {
-				groupBlock = append(groupBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkActive, v)))
+				groupBlock = append(groupBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", check, label)))
 			} else if active {
-				groupBlock = append(groupBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(checkActive), v))
+				groupBlock = append(groupBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(check), label))
 			} else if focused {
-				groupBlock = append(groupBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				groupBlock = append(groupBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
 			} else {
-				groupBlock = append(groupBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				groupBlock = append(groupBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
 			}
 		}
 
 		{
 			if len(otherBlock) < 1 {
-				otherBlock = append(otherBlock, styles.PermissionsBlockItem.Copy().Foreground(lipgloss.Color(ColorYellow)).Render("[Other]"))
+				otherBlock = append(otherBlock, styles.PermissionsBlockTitle.Render("[Other]"))
 				otherBlock = append(otherBlock, styles.PermissionsBlockItem.Render(strings.Repeat("-", 7)))
 			}
 
 			focused := p.cursor == 2 && p.values[currBlock.cursor] == v
-			active := common.IncludesString(p.blocks[2].selected, v)
+			active, check := p.itemState(2, v)
 
 			if focused && active {
-				otherBlock = append(otherBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkActive, v)))
+				otherBlock = append(otherBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", check, label)))
 			} else if active {
-				otherBlock = append(otherBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(checkActive), v))
+				otherBlock = append(otherBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(check), label))
 			} else if focused {
-				otherBlock = append(otherBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				otherBlock = append(otherBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
 			} else {
-				otherBlock = append(otherBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, v)))
+				otherBlock = append(otherBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, label)))
+			}
+		}
+
+		if i < len(p.special) {
+			if len(specialBlock) < 1 {
+				specialBlock = append(specialBlock, styles.PermissionsBlockTitle.Render("[Special]"))
+				specialBlock = append(specialBlock, styles.PermissionsBlockItem.Render(strings.Repeat("-", 9)))
+			}
+
+			s := p.special[i]
+			focused := p.cursor == 3 && currBlock.cursor == i
+			active, check := p.itemState(3, s)
+
+			if focused && active {
+				specialBlock = append(specialBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", check, s)))
+			} else if active {
+				specialBlock = append(specialBlock, fmt.Sprintf("%s %s", styles.PermissionsActiveBlockItem.Render(check), s))
+			} else if focused {
+				specialBlock = append(specialBlock, styles.PermissionsActiveBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, s)))
+			} else {
+				specialBlock = append(specialBlock, styles.PermissionsBlockItem.Render(fmt.Sprintf("%s %s", checkInactive, s)))
 			}
 		}
 	}
 
 	var (
-		ownerBlockStyle = styles.PermissionsBlock
-		groupBlockStyle = styles.PermissionsBlock
-		otherBlockStyle = styles.PermissionsBlock
+		ownerBlockStyle   = styles.PermissionsBlock
+		groupBlockStyle   = styles.PermissionsBlock
+		otherBlockStyle   = styles.PermissionsBlock
+		specialBlockStyle = styles.PermissionsBlock
 	)
 
 	if p.cursor == 0 {
@@ -192,6 +627,10 @@ func (p *Permissions) renderPermissions() string {
 		otherBlockStyle = styles.PermissionsActiveBlock
 	}
 
+	if p.cursor == 3 {
+		specialBlockStyle = styles.PermissionsActiveBlock
+	}
+
 	blocks := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		ownerBlockStyle.Render(lipgloss.JoinVertical(
@@ -206,6 +645,10 @@ func (p *Permissions) renderPermissions() string {
 			lipgloss.Left,
 			otherBlock...,
 		)),
+		specialBlockStyle.Copy().Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			specialBlock...,
+		)),
 	)
 
 	return lipgloss.JoinVertical(
@@ -214,3 +657,31 @@ func (p *Permissions) renderPermissions() string {
 		blocks,
 	)
 }
+
+// itemState reports whether an item in a block is active and the checkbox
+// symbol to render for it, which is tri-state in relative mode
+func (p *Permissions) itemState(blockIndex int, item string) (bool, string) {
+	if !p.relative {
+		return common.IncludesString(p.blocks[blockIndex].selected, item), checkActive
+	}
+
+	switch p.blocks[blockIndex].changes[item] {
+	case generate.Add:
+		return true, checkAdd
+
+	case generate.Remove:
+		return true, checkRemove
+	}
+
+	return false, checkActive
+}
+
+// getPermissionLabel returns the text shown for a permission value, shortened
+// where the value doesn't fit in a block
+func getPermissionLabel(value string) string {
+	if value == "Execute (dirs only)" {
+		return "Exec (dirs)"
+	}
+
+	return value
+}
@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
 )
 
 // colors
@@ -19,6 +25,97 @@ const (
 	ColorSubtleDark  = Color("#383838")
 )
 
+// Theme is the palette the styles are built from
+type Theme struct {
+	// Primary is the background of section headers and the border of the focused block
+	Primary lipgloss.TerminalColor
+	// Accent marks focused and selected items and errors
+	Accent lipgloss.TerminalColor
+	// Highlight is used for the banner, footer and block titles
+	Highlight lipgloss.TerminalColor
+	// Success marks added bits and successful results
+	Success lipgloss.TerminalColor
+	// HeaderText is the text of section headers
+	HeaderText lipgloss.TerminalColor
+	// Subtle is the background of the footer and the banner pattern
+	Subtle lipgloss.TerminalColor
+	// Muted is used for borders, hints and low severity warnings
+	Muted lipgloss.TerminalColor
+}
+
+// Themes are the available themes by name
+var Themes = map[string]Theme{
+	"default": {
+		Primary:    lipgloss.Color(ColorPurple),
+		Accent:     lipgloss.Color(ColorRed),
+		Highlight:  lipgloss.Color(ColorYellow),
+		Success:    lipgloss.Color(ColorGreen),
+		HeaderText: lipgloss.Color(ColorGray50),
+		Subtle:     lipgloss.AdaptiveColor{Light: ColorSubtleLight, Dark: ColorSubtleDark},
+		Muted:      lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"},
+	},
+	"ocean": {
+		Primary:    lipgloss.Color("#1F6FEB"),
+		Accent:     lipgloss.Color("#FF9E64"),
+		Highlight:  lipgloss.Color("#7DCFFF"),
+		Success:    lipgloss.Color("#9ECE6A"),
+		HeaderText: lipgloss.Color("#F0F6FC"),
+		Subtle:     lipgloss.AdaptiveColor{Light: "#D0E4F5", Dark: "#1B2A3A"},
+		Muted:      lipgloss.AdaptiveColor{Light: "#8B9BB0", Dark: "#56677D"},
+	},
+	"mono": {
+		Primary:    lipgloss.AdaptiveColor{Light: "#303030", Dark: "#D0D0D0"},
+		Accent:     lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+		Highlight:  lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+		Success:    lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+		HeaderText: lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"},
+		Subtle:     lipgloss.AdaptiveColor{Light: ColorSubtleLight, Dark: ColorSubtleDark},
+		Muted:      lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"},
+	},
+}
+
+var theme = Themes["default"]
+
+// SetTheme selects the theme the styles are built from
+func SetTheme(name string) error {
+	t, ok := Themes[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(Themes))
+
+		for k := range Themes {
+			names = append(names, k)
+		}
+
+		sort.Strings(names)
+
+		return fmt.Errorf("unknown theme '%s', available themes are: %s", name, strings.Join(names, ", "))
+	}
+
+	theme = t
+
+	return nil
+}
+
+// colorProfile detects the colors supported by the terminal w is attached to,
+// following the environment variables termenv uses for stdout
+func colorProfile(w io.Writer) termenv.Profile {
+	f, ok := w.(*os.File)
+	if !ok || !isatty.IsTerminal(f.Fd()) || termenv.EnvNoColor() {
+		return termenv.Ascii
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return termenv.TrueColor
+	}
+
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return termenv.ANSI256
+	}
+
+	return termenv.ANSI
+}
+
 // symbols
 const (
 	// radioActive   = "(o)"
@@ -26,7 +123,10 @@ const (
 	radioInactive = "(o)"
 	checkActive   = "[✓]"
 	checkInactive = "[ ]"
+	checkAdd      = "[+]"
+	checkRemove   = "[-]"
 	snowflake     = "❄ "
+	warning       = "⚠"
 )
 
 type Styles struct {
@@ -36,37 +136,79 @@ type Styles struct {
 
 	Footer        lipgloss.Style
 	FooterContent lipgloss.Style
+	FooterError   lipgloss.Style
+	FooterUmask   lipgloss.Style
+
+	LintHigh   lipgloss.Style
+	LintMedium lipgloss.Style
+	LintLow    lipgloss.Style
 
 	OptionsContainer  func(opts strings.Builder) string
 	OptionsHeader     lipgloss.Style
 	OptionsItem       lipgloss.Style
 	OptionsActiveItem lipgloss.Style
 
+	PresetsContainer  func(presets strings.Builder) string
+	PresetsHeader     lipgloss.Style
+	PresetsItem       lipgloss.Style
+	PresetsActiveItem lipgloss.Style
+	PresetsMode       lipgloss.Style
+
 	CommandModeContainer  func(modes ...string) string
 	CommandModeHeader     lipgloss.Style
 	CommandModeItem       lipgloss.Style
 	CommandModeActiveItem lipgloss.Style
 
-	PathTypeContainer  func(paths ...string) string
+	PathTypeContainer  func(title string, paths ...string) string
 	PathTypeHeader     lipgloss.Style
 	PathTypeItem       lipgloss.Style
 	PathTypeActiveItem lipgloss.Style
 
+	ModeInputHeader lipgloss.Style
+	ModeInputError  lipgloss.Style
+
 	PermissionsHeader          lipgloss.Style
 	PermissionsBlock           lipgloss.Style
 	PermissionsActiveBlock     lipgloss.Style
 	PermissionsBlockItem       lipgloss.Style
+	PermissionsBlockTitle      lipgloss.Style
 	PermissionsActiveBlockItem lipgloss.Style
+
+	DiffHeader  lipgloss.Style
+	DiffSource  lipgloss.Style
+	DiffAdded   lipgloss.Style
+	DiffRemoved lipgloss.Style
+	DiffChanged lipgloss.Style
+
+	FilesHeader     lipgloss.Style
+	FilesItem       lipgloss.Style
+	FilesActiveItem lipgloss.Style
+	FilesMore       lipgloss.Style
+
+	HistoryHeader     lipgloss.Style
+	HistoryItem       lipgloss.Style
+	HistoryActiveItem lipgloss.Style
+	HistoryTime       lipgloss.Style
+
+	PreviewHeader lipgloss.Style
+	PreviewError  lipgloss.Style
+	PreviewMore   lipgloss.Style
+
+	ResultsHeader  lipgloss.Style
+	ResultsSuccess lipgloss.Style
+	ResultsFailure lipgloss.Style
+	ResultsHint    lipgloss.Style
 }
 
 func GetStyles() *Styles {
 	s := new(Styles)
+	t := theme
 
-	s.BannerText = lipgloss.NewStyle().Foreground(lipgloss.Color(ColorYellow))
+	s.BannerText = lipgloss.NewStyle().Foreground(t.Highlight)
 
 	s.BannerContent = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color(ColorPurple)).
+		BorderForeground(t.Primary).
 		Padding(0, 1).
 		BorderTop(true).
 		BorderRight(true).
@@ -80,24 +222,34 @@ func GetStyles() *Styles {
 		lipgloss.Center,
 		s.BannerContent.Render(s.BannerText.Render("chmod-cli v.0.1.0")),
 		lipgloss.WithWhitespaceChars(snowflake),
-		lipgloss.WithWhitespaceForeground(lipgloss.AdaptiveColor{Light: ColorSubtleLight, Dark: ColorSubtleDark}),
+		lipgloss.WithWhitespaceForeground(t.Subtle),
 	)
 
 	s.Footer = lipgloss.NewStyle().
 		Width(55).
-		Foreground(lipgloss.Color(ColorYellow)).
-		Background(lipgloss.AdaptiveColor{Light: ColorSubtleLight, Dark: ColorSubtleDark}).
+		Foreground(t.Highlight).
+		Background(t.Subtle).
 		Padding(0, 1)
 
 	s.FooterContent = lipgloss.NewStyle().Bold(true)
 
+	s.FooterError = s.FooterContent.Copy().Foreground(t.Accent)
+
+	s.FooterUmask = lipgloss.NewStyle().Foreground(t.Muted)
+
+	s.LintHigh = lipgloss.NewStyle().Width(55).Padding(0, 1).Foreground(t.Accent)
+
+	s.LintMedium = s.LintHigh.Copy().Foreground(t.Highlight)
+
+	s.LintLow = s.LintHigh.Copy().Foreground(t.Muted)
+
 	s.OptionsHeader = lipgloss.NewStyle().
-		Foreground(lipgloss.Color(ColorGray50)).
-		Background(lipgloss.Color(ColorPurple)).
+		Foreground(t.HeaderText).
+		Background(t.Primary).
 		Padding(0, 3).Bold(true)
 
 	s.OptionsItem = lipgloss.NewStyle().Padding(0)
-	s.OptionsActiveItem = s.OptionsItem.Copy().Foreground(lipgloss.Color(ColorRed))
+	s.OptionsActiveItem = s.OptionsItem.Copy().Foreground(t.Accent)
 
 	s.OptionsContainer = func(opts strings.Builder) string {
 		return lipgloss.JoinVertical(
@@ -107,67 +259,150 @@ func GetStyles() *Styles {
 		)
 	}
 
+	s.PresetsHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.PresetsItem = lipgloss.NewStyle().Padding(0)
+	s.PresetsActiveItem = s.PresetsItem.Copy().Foreground(t.Accent)
+	s.PresetsMode = lipgloss.NewStyle().Foreground(t.Muted)
+
+	s.PresetsContainer = func(presets strings.Builder) string {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			s.PresetsHeader.Render("Presets"),
+			presets.String(),
+		)
+	}
+
 	s.CommandModeHeader = lipgloss.NewStyle().
-		Foreground(lipgloss.Color(ColorGray50)).
-		Background(lipgloss.Color(ColorPurple)).
+		Foreground(t.HeaderText).
+		Background(t.Primary).
 		Padding(0, 3).Bold(true)
 
 	s.CommandModeItem = lipgloss.NewStyle().Padding(0)
 
-	s.CommandModeActiveItem = s.CommandModeItem.Copy().Foreground(lipgloss.Color(ColorRed))
+	s.CommandModeActiveItem = s.CommandModeItem.Copy().Foreground(t.Accent)
 
 	s.CommandModeContainer = func(modes ...string) string {
 		// hacky way to add spacing between horizontal elements
 		// because margin's not giving expected behavior
-		modes = append(modes, lipgloss.NewStyle().Render("  "))
+		spaced := []string{}
 
-		modes[1], modes[2] = modes[2], modes[1]
+		for i, v := range modes {
+			if i > 0 {
+				spaced = append(spaced, lipgloss.NewStyle().Render("  "))
+			}
+
+			spaced = append(spaced, v)
+		}
 
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
 			s.CommandModeHeader.Render("Command Mode"),
-			lipgloss.JoinHorizontal(lipgloss.Top, modes...),
+			lipgloss.JoinHorizontal(lipgloss.Top, spaced...),
 		)
 	}
 
 	s.PathTypeHeader = lipgloss.NewStyle().
-		Foreground(lipgloss.Color(ColorGray50)).
-		Background(lipgloss.Color(ColorPurple)).
+		Foreground(t.HeaderText).
+		Background(t.Primary).
 		Padding(0, 3).Bold(true)
 
 	s.PathTypeItem = lipgloss.NewStyle().Padding(0)
 
-	s.PathTypeActiveItem = s.PathTypeItem.Copy().Foreground(lipgloss.Color(ColorRed))
+	s.PathTypeActiveItem = s.PathTypeItem.Copy().Foreground(t.Accent)
 
-	s.PathTypeContainer = func(paths ...string) string {
+	s.PathTypeContainer = func(title string, paths ...string) string {
 		paths = append(paths, lipgloss.NewStyle().Render("  "))
 
 		paths[1], paths[2] = paths[2], paths[1]
 
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
-			s.PathTypeHeader.Render("Path Type"),
+			s.PathTypeHeader.Render(title),
 			lipgloss.JoinHorizontal(lipgloss.Top, paths...),
 		)
 	}
 
+	s.ModeInputHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.ModeInputError = lipgloss.NewStyle().Width(55).Foreground(t.Accent)
+
 	s.PermissionsHeader = lipgloss.NewStyle().
-		Foreground(lipgloss.Color(ColorGray50)).
-		Background(lipgloss.Color(ColorPurple)).
+		Foreground(t.HeaderText).
+		Background(t.Primary).
 		Padding(0, 3).Bold(true)
 
 	s.PermissionsBlock = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), true).
-		BorderForeground(lipgloss.AdaptiveColor{Light: "#969B86", Dark: "#696969"}).
-		MarginRight(2).
-		Height(5).
-		Width(15)
+		BorderForeground(t.Muted).
+		MarginRight(1).
+		Height(6).
+		Width(17)
 
-	s.PermissionsActiveBlock = s.PermissionsBlock.Copy().BorderForeground(lipgloss.Color(ColorPurple))
+	s.PermissionsActiveBlock = s.PermissionsBlock.Copy().BorderForeground(t.Primary)
 
 	s.PermissionsBlockItem = lipgloss.NewStyle().PaddingLeft(2)
 
-	s.PermissionsActiveBlockItem = s.PermissionsBlockItem.Copy().Foreground(lipgloss.Color(ColorRed))
+	s.PermissionsActiveBlockItem = s.PermissionsBlockItem.Copy().Foreground(t.Accent)
+
+	s.PermissionsBlockTitle = s.PermissionsBlockItem.Copy().Foreground(t.Highlight)
+
+	s.DiffHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.DiffSource = lipgloss.NewStyle().Foreground(t.Highlight)
+
+	s.DiffAdded = lipgloss.NewStyle().Foreground(t.Success).Bold(true)
+
+	s.DiffRemoved = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+
+	s.DiffChanged = lipgloss.NewStyle().Foreground(t.Highlight).Bold(true)
+
+	s.FilesHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.FilesItem = lipgloss.NewStyle().Padding(0)
+	s.FilesActiveItem = s.FilesItem.Copy().Foreground(t.Accent)
+	s.FilesMore = lipgloss.NewStyle().Foreground(t.Muted)
+
+	s.HistoryHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.HistoryItem = lipgloss.NewStyle().Padding(0)
+	s.HistoryActiveItem = s.HistoryItem.Copy().Foreground(t.Accent)
+	s.HistoryTime = lipgloss.NewStyle().Foreground(t.Muted)
+
+	s.PreviewHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.PreviewError = lipgloss.NewStyle().Foreground(t.Accent)
+
+	s.PreviewMore = lipgloss.NewStyle().Foreground(t.Muted)
+
+	s.ResultsHeader = lipgloss.NewStyle().
+		Foreground(t.HeaderText).
+		Background(t.Primary).
+		Padding(0, 3).Bold(true)
+
+	s.ResultsSuccess = lipgloss.NewStyle().Foreground(t.Success)
+
+	s.ResultsFailure = lipgloss.NewStyle().Foreground(t.Accent)
+
+	s.ResultsHint = lipgloss.NewStyle().Foreground(t.Muted)
 
 	return s
 }
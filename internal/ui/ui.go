@@ -1,45 +1,133 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
 	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/history"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/lint"
 	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
-const NumSections = 4
+const NumSections = 8
 const ResetCommandDuration = time.Second * 3
 
+// PreviewLimit is the number of entries listed in the preview
+const PreviewLimit = 6
+
+// UndoLimit is the number of edits that can be undone
+const UndoLimit = 100
+
+// FilesLimit is the number of entries the file picker shows at once
+const FilesLimit = 8
+
+// HistoryLimit is the number of history entries shown at once
+const HistoryLimit = 5
+
 type Section int
 
 const (
 	OptionsSection Section = iota
 	CommandModeSection
 	PathTypeSection
+	ModeInputSection
 	PermissionsSection
+	PresetsSection
+	FilesSection
+	HistorySection
 )
 
 func (s Section) String() string {
-	return [...]string{"options", "command-mode", "path-type", "permissions"}[s]
+	return [...]string{"options", "command-mode", "path-type", "mode-input", "permissions", "presets", "files", "history"}[s]
 }
 
 type Model struct {
-	cursor      int
-	section     Section
-	options     *Options
-	mode        *CommandMode
-	path        *PathType
-	permissions *Permissions
+	cursor           int
+	section          Section
+	options          *Options
+	mode             *CommandMode
+	path             *PathType
+	input            *ModeInput
+	permissions      *Permissions
+	presets          *Presets
+	files            *Files
+	history          *History
+	state            *generate.State
+	keys             *KeyMap
+	help             help.Model
+	err              error
+	results          []ApplyResult
+	source           string
+	sourceMode       fs.FileMode
+	hasSource        bool
+	dirState         *generate.State
+	recursiveCommand string
+	recursiveErr     error
+	fromPreset       bool
+	fixedPathType    bool
+	print            bool
+	done             bool
+	restore          string
+	preview          []PreviewEntry
+	previewTotal     int
+	undo             []Snapshot
+	redo             []Snapshot
+	historyPath      string
+}
+
+// Snapshot stores the permissions being edited so an edit can be undone,
+// dirState is nil outside recursive mode. Presets switch the path type and
+// command mode, so they are kept as well
+type Snapshot struct {
 	state       *generate.State
-	keys        *KeyMap
-	help        help.Model
+	dirState    *generate.State
+	pathType    string
+	commandMode string
+}
+
+// Settings configure the tui. Targets are the paths the mode can be applied to
+// and Source is the path the permissions are prefilled from (defaults to PWD).
+// Presets replace the built-in presets when given. Option, CommandMode,
+// PathType and Preset select the initial values by name, the built-in
+// defaults are kept when they're empty. In Print mode quitting with q returns
+// the command. Input is where keys are read from and defaults to stdin,
+// Output is where the tui is drawn and defaults to stdout.
+// Copied, applied and printed commands are recorded in the history file at
+// HistoryPath, there is no history when it's empty
+type Settings struct {
+	Targets     []string
+	Source      string
+	Presets     []generate.Preset
+	Option      string
+	CommandMode string
+	PathType    string
+	Preset      string
+	Theme       string
+	Print       bool
+	Input       io.Reader
+	Output      io.Writer
+	HistoryPath string
 }
 
+var (
+	optionValues      = []string{"Verbose", "Changes", "Silent", "Default"}
+	commandModeValues = []string{"Octal", "Symbolic", "Relative"}
+	pathTypeValues    = []string{"File", "Directory"}
+)
+
 // Options store the state for selected options
 type Options struct {
 	values   []string
@@ -56,234 +144,1242 @@ type CommandMode struct {
 
 // PathType stores the state for selected path type
 type PathType struct {
-	values   []string
-	selected string
-	cursor   int
+	values    []string
+	selected  string
+	cursor    int
+	recursive bool
+}
+
+// ModeInput stores the state for the field a mode can be typed in
+type ModeInput struct {
+	field textinput.Model
+	err   error
 }
 
 // Permissions store the state for selected permissions
 type Permissions struct {
-	blocks []PermissionsBlock
+	blocks   []PermissionsBlock
+	cursor   int
+	values   []string
+	special  []string
+	relative bool
+}
+
+// Presets store the state for the presets list
+type Presets struct {
+	values []generate.Preset
 	cursor int
-	values []string
+}
+
+// Files store the state for the file picker, selected holds the targets
+type Files struct {
+	dir      string
+	entries  []FileEntry
+	selected []string
+	cursor   int
+	offset   int
+}
+
+// History store the state for the history list, most recent first
+type History struct {
+	entries []history.Entry
+	cursor  int
+	offset  int
+}
+
+// FileEntry is an entry of the directory shown in the file picker
+type FileEntry struct {
+	Name  string
+	IsDir bool
 }
 
 // PermissionsBlock store the state for each permissions block
 type PermissionsBlock struct {
 	cursor   int
 	selected []string
+	changes  map[string]generate.Operation
 }
 
 type PWDPermissionMsg string
 
+// SourceModeMsg carries the mode of the path the permissions are prefilled from
+type SourceModeMsg struct {
+	Path string
+	Mode fs.FileMode
+}
+
 type UpdateCommandMsg struct {
-	User   generate.User
-	Access generate.Access
-	Active bool
+	User      generate.User
+	Access    generate.Access
+	Special   generate.Special
+	Active    bool
+	Relative  bool
+	Operation generate.Operation
 }
 
 type CopyCommandMsg struct{}
 
+// CopyHistoryMsg carries the history entry to copy again
+type CopyHistoryMsg history.Entry
+
+// HistoryMsg carries the entries of the history file, most recent first
+type HistoryMsg []history.Entry
+
+// PresetMsg carries the preset picked from the presets list
+type PresetMsg generate.Preset
+
 type ResetCommandMsg string
 
-func InitScreen() error {
-	model := createModel()
-	p := tea.NewProgram(model, tea.WithAltScreen())
+// ErrorMsg reports a failure that should be shown in the footer
+type ErrorMsg struct {
+	Err error
+}
+
+// ApplyResult stores the outcome of applying the mode to a single target,
+// Changed is false when the target already had the mode
+type ApplyResult struct {
+	Path    string
+	Mode    fs.FileMode
+	Changed bool
+	Err     error
+}
+
+// ApplyResultMsg carries the command that was applied and its results
+type ApplyResultMsg struct {
+	Command string
+	Results []ApplyResult
+}
+
+// FilesMsg carries the entries of the directory opened in the file picker
+type FilesMsg struct {
+	Dir     string
+	Entries []FileEntry
+}
+
+// PreviewEntry stores the current mode of a path listed in the preview
+type PreviewEntry struct {
+	Path string
+	Mode fs.FileMode
+	Err  error
+}
+
+// PreviewMsg carries the first entries of the preview and the number of
+// entries there are in total
+type PreviewMsg struct {
+	Entries []PreviewEntry
+	Total   int
+}
+
+// InitScreen starts the tui with the given settings. In print mode it returns
+// the command when the user quits with q, and an empty string when they cancel
+func InitScreen(settings Settings) (string, error) {
+	if err := settings.normalize(); err != nil {
+		return "", err
+	}
+
+	if settings.Theme != "" {
+		if err := SetTheme(settings.Theme); err != nil {
+			return "", err
+		}
+	}
+
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+
+	// a custom input stops bubbletea from opening the tty when stdin isn't one
+	if settings.Input != nil && settings.Input != os.Stdin {
+		opts = append(opts, tea.WithInput(settings.Input))
+	}
+
+	if settings.Output != nil && settings.Output != os.Stdout {
+		// lipgloss only detects the colors supported on stdout
+		lipgloss.SetColorProfile(colorProfile(settings.Output))
+		opts = append(opts, tea.WithOutput(settings.Output))
+	}
 
-	return p.Start()
+	model, err := tea.NewProgram(createModel(settings), opts...).StartReturningModel()
+	if err != nil {
+		return "", err
+	}
+
+	command, err := model.(Model).printCommand()
+	if err != nil || command == "" || settings.HistoryPath == "" {
+		return command, err
+	}
+
+	// the history is best effort, the command is printed either way
+	_ = history.Append(settings.HistoryPath, history.NewEntry(history.Print, command, model.(Model).files.selected))
+
+	return command, nil
 }
 
-func createModel() tea.Model {
-	optionValues := []string{"Verbose", "Changes", "Silent", "Default"}
+// printCommand returns the command to print once the tui has exited
+func (m Model) printCommand() (string, error) {
+	if !m.print || !m.done {
+		return "", nil
+	}
+
+	if m.err != nil {
+		return "", m.err
+	}
+
+	if m.command() == "" {
+		return "", errors.New("nothing to print, mark permissions to add or remove first")
+	}
+
+	return m.command(), nil
+}
+
+// command returns the generated command, which a notice such as "copied!" may
+// be shown in place of
+func (m Model) command() string {
+	if m.restore != "" {
+		return m.restore
+	}
+
+	return m.state.Command
+}
+
+// normalize checks the initial values of the settings and replaces them with
+// their canonical names, names are matched case-insensitively
+func (s *Settings) normalize() error {
+	for _, v := range []struct {
+		name   string
+		value  *string
+		values []string
+	}{
+		{"option", &s.Option, optionValues},
+		{"command mode", &s.CommandMode, commandModeValues},
+		{"path type", &s.PathType, pathTypeValues},
+	} {
+		if *v.value == "" {
+			continue
+		}
+
+		i := findFold(v.values, *v.value)
+		if i < 0 {
+			return fmt.Errorf("invalid %s '%s': expected one of %s", v.name, *v.value, strings.ToLower(strings.Join(v.values, ", ")))
+		}
+
+		*v.value = v.values[i]
+	}
+
+	if s.Preset == "" {
+		return nil
+	}
+
+	for _, p := range s.presets() {
+		if strings.EqualFold(p.Name, s.Preset) {
+			s.Preset = p.Name
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no preset named '%s'", s.Preset)
+}
+
+func (s Settings) presets() []generate.Preset {
+	if s.Presets != nil {
+		return s.Presets
+	}
+
+	return generate.Presets
+}
+
+// findFold returns the index of val in s, compared case-insensitively
+func findFold(s []string, val string) int {
+	for i, v := range s {
+		if strings.EqualFold(v, val) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func createModel(settings Settings) tea.Model {
 	options := &Options{
 		values:   optionValues,
-		selected: optionValues[3],
+		selected: orDefault(settings.Option, optionValues[3]),
 	}
 
-	commandModeValues := []string{"Octal", "Symbolic"}
 	commandMode := &CommandMode{
 		values:   commandModeValues,
-		selected: commandModeValues[1],
+		selected: orDefault(settings.CommandMode, commandModeValues[1]),
 		cursor:   -1,
 	}
 
-	pathTypeValues := []string{"File", "Directory"}
 	pathType := &PathType{
 		values:   pathTypeValues,
-		selected: pathTypeValues[0],
+		selected: orDefault(settings.PathType, pathTypeValues[0]),
 		cursor:   -1,
 	}
 
-	blocks := make([]PermissionsBlock, 3)
+	field := textinput.NewModel()
+	field.Placeholder = "640, rw-r----- or g+w"
+	field.CharLimit = 32
+	field.SetCursorMode(textinput.CursorStatic)
+
+	input := &ModeInput{field: field}
+
+	blocks := make([]PermissionsBlock, 4)
 	blocks[0].cursor = -1
 
-	permissionValues := []string{"Read", "Write", "Execute"}
+	permissionValues := []string{"Read", "Write", "Execute", "Execute (dirs only)"}
+	specialValues := []string{"SetUID", "SetGID", "Sticky"}
 	permissions := &Permissions{
-		values: permissionValues,
-		blocks: blocks,
+		values:   permissionValues,
+		special:  specialValues,
+		blocks:   blocks,
+		cursor:   -1,
+		relative: commandMode.selected == "Relative",
+	}
+
+	presets := &Presets{
+		values: settings.presets(),
 		cursor: -1,
 	}
 
+	commandHistory := &History{
+		entries: []history.Entry{},
+		cursor:  -1,
+	}
+
+	files := &Files{
+		dir:      ".",
+		selected: append([]string{}, settings.Targets...),
+		cursor:   -1,
+	}
+
 	state := generate.NewState()
 
 	keyMap := NewKeyMap()
 
+	if settings.Print {
+		keyMap.Quit = key.NewBinding(
+			key.WithKeys("q"),
+			key.WithHelp("q", "print & quit"),
+		)
+		keyMap.Cancel.SetEnabled(true)
+	}
+
 	help := help.NewModel()
 	help.Width = 55
 
-	return Model{
+	m := Model{
 		cursor:      0,
 		section:     OptionsSection,
 		options:     options,
 		mode:        commandMode,
 		path:        pathType,
+		input:       input,
 		permissions: permissions,
+		presets:     presets,
+		files:       files,
+		history:     commandHistory,
 		state:       state,
 		keys:        keyMap,
 		help:        help,
+		source:      settings.Source,
+		print:       settings.Print,
+		historyPath: settings.HistoryPath,
+		// the working directory is always a directory, so only a source the
+		// user picked overrides the configured path type
+		fixedPathType: settings.PathType != "" && settings.Source == "",
+	}
+
+	for _, p := range presets.values {
+		if settings.Preset != "" && p.Name == settings.Preset && m.applyPreset(p) == nil {
+			m.fromPreset = true
+		}
 	}
+
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(getPWDPermission)
+	cmds := []tea.Cmd{getPWDPermission, getSourceMode(m.source), getPreview(m.files.selected), readDir(m.files.dir)}
+
+	if m.historyPath != "" {
+		cmds = append(cmds, loadHistory(m.historyPath))
+	}
+
+	if m.fromPreset {
+		cmds = append(cmds, updateCommand(generate.User(""), generate.Access(""), false))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// the apply results stay on screen until the next key press
+		if m.results != nil {
+			return m, tea.Quit
+		}
+
+		// keys are typed into the mode field, except the ones leaving it
+		if m.section == ModeInputSection && !common.IncludesString([]string{"tab", "shift+tab", "esc", "ctrl+c", "ctrl+r"}, msg.String()) {
+			return m, updateModeInput(&m, msg)
+		}
+
+		switch msg.String() {
+		case "esc", "q":
+			// in print mode esc cancels instead of printing the command
+			m.done = msg.String() == "q"
+
+			// the command may be quit before the first update has built it
+			if m.print && m.done {
+				m.restore = ""
+				m.err = buildCommand(&m)
+			}
+
+			return m, tea.Quit
+
+		case "a":
+			if len(m.files.selected) == 0 {
+				m.err = errors.New("no targets to apply to, select paths in the files section or pass them as arguments")
+				break
+			}
+
+			// the command shown may be stale when the state can't be built
+			if m.err != nil {
+				break
+			}
+
+			if strings.EqualFold(m.state.Command, "") {
+				m.err = errors.New("nothing to apply yet, select some permissions first")
+				break
+			}
+
+			if m.dirState != nil {
+				m.err = errors.New("apply is not available in recursive mode, copy the command instead")
+				break
+			}
+
+			// later key presses change the state while the command runs
+			return m, applyMode(append([]string{}, m.files.selected...), m.state.Clone(), m.mode.selected, m.command())
+
+		case "up", "down", "left", "right", "enter":
+			if m.section == OptionsSection {
+				return m, m.options.updateOptions(msg.String())
+			}
+
+			if m.section == CommandModeSection {
+				cmd := m.mode.updateCommandMode(msg.String())
+				m.permissions.relative = m.mode.selected == "Relative"
+
+				return m, cmd
+			}
+
+			if m.section == PathTypeSection {
+				cmd := m.path.updatePathType(msg.String())
+				m.syncBlocks(m.activeState())
+
+				return m, cmd
+			}
+
+			if m.section == PermissionsSection {
+				return m, m.permissions.updatePermissions(msg.String())
+			}
+
+			if m.section == PresetsSection {
+				return m, m.presets.updatePresets(msg.String())
+			}
+
+			if m.section == FilesSection {
+				return m, m.files.updateFiles(msg.String())
+			}
+
+			if m.section == HistorySection {
+				return m, m.history.updateHistory(msg.String())
+			}
+
+		case "tab", " ", "shift+tab":
+			switchSection(&m, msg.String())
+
+		case "?":
+			m.help.ShowAll = !m.help.ShowAll
+
+		case "r":
+			toggleRecursive(&m)
+
+			return m, updateCommand(generate.User(""), generate.Access(""), false)
+
+		case "u":
+			if undoEdit(&m) {
+				return m, updateCommand(generate.User(""), generate.Access(""), false)
+			}
+
+		case "ctrl+r":
+			if redoEdit(&m) {
+				return m, updateCommand(generate.User(""), generate.Access(""), false)
+			}
+
+		case "ctrl+c":
+			if !strings.EqualFold(m.command(), "") {
+				return m, copyCommand()
+			}
+		}
+
+	case PWDPermissionMsg:
+		m.state.PWD = string(msg)
+
+	case SourceModeMsg:
+		m.sourceMode = msg.Mode
+		m.hasSource = true
+
+		// the initial preset takes precedence, the source is only used for the diff
+		if !m.fromPreset {
+			m.prefill(msg.Mode)
+		}
+
+		return m, updateCommand(generate.User(""), generate.Access(""), false)
+
+	case UpdateCommandMsg:
+		if !strings.EqualFold(string(msg.User), "") || !strings.EqualFold(string(msg.Special), "") {
+			recordEdit(&m, takeSnapshot(&m))
+		}
+
+		state := m.activeState()
+
+		if msg.Relative {
+			if !strings.EqualFold(string(msg.User), "") {
+				state.Changes[msg.User][msg.Access] = msg.Operation
+			}
+
+			if !strings.EqualFold(string(msg.Special), "") {
+				state.SpecialChanges[msg.Special] = msg.Operation
+			}
+		} else {
+			if !strings.EqualFold(string(msg.User), "") {
+				state.Users[msg.User][msg.Access] = msg.Active
+			}
+
+			if !strings.EqualFold(string(msg.Special), "") {
+				state.Special[msg.Special] = msg.Active
+			}
+		}
+
+		m.syncModeInput()
+
+		m.err = buildCommand(&m)
+
+	case PresetMsg:
+		snapshot := takeSnapshot(&m)
+
+		if err := m.applyPreset(generate.Preset(msg)); err != nil {
+			m.err = err
+			break
+		}
+
+		recordEdit(&m, snapshot)
+
+		return m, updateCommand(generate.User(""), generate.Access(""), false)
+
+	case CopyCommandMsg:
+		if err := common.CopyToClipboard(m.command()); err != nil {
+			return m, showNotice(&m, "error copying to clipboard")
+		}
+
+		return m, tea.Batch(recordHistory(&m, history.Copy), showNotice(&m, "copied!"))
+
+	case CopyHistoryMsg:
+		if err := common.CopyToClipboard(msg.Command); err != nil {
+			return m, showNotice(&m, "error copying to clipboard")
+		}
+
+		return m, showNotice(&m, "copied!")
+
+	case HistoryMsg:
+		m.history.entries = msg
+
+	case ResetCommandMsg:
+		m.state.Command = string(msg)
+		m.restore = ""
+
+	case ErrorMsg:
+		m.err = msg.Err
+
+	case ApplyResultMsg:
+		m.results = msg.Results
+
+		changed := []string{}
+
+		for _, v := range msg.Results {
+			if v.Err == nil && v.Changed {
+				changed = append(changed, v.Path)
+			}
+		}
+
+		if len(changed) > 0 {
+			return m, recordEntry(&m, history.NewEntry(history.Apply, msg.Command, changed))
+		}
+
+	case FilesMsg:
+		m.files.dir = msg.Dir
+		m.files.entries = msg.Entries
+		m.files.offset = 0
+
+		if m.files.cursor >= 0 {
+			m.files.cursor = 0
+		}
+
+	case PreviewMsg:
+		m.preview = msg.Entries
+		m.previewTotal = msg.Total
+	}
+
+	return m, nil
+}
+
+// activeState returns the state being edited, which is the directory state
+// when recursive mode is on and the Directory path type is selected
+func (m Model) activeState() *generate.State {
+	if m.dirState != nil && m.path.selected == "Directory" {
+		return m.dirState
+	}
+
+	return m.state
+}
+
+// previewMode returns the mode a path with the given mode would end up with,
+// in recursive mode directories get the directory state
+func (m Model) previewMode(mode fs.FileMode) fs.FileMode {
+	state := m.state
+
+	if m.dirState != nil && mode.IsDir() {
+		state = m.dirState
+	}
+
+	return state.ResolveMode(m.mode.selected, mode)
+}
+
+// lintFindings evaluates the mode being built for the selected path type,
+// relative changes can only be linted against the source mode
+func (m Model) lintFindings() []lint.Finding {
+	state := m.activeState()
+
+	if m.mode.selected != "Relative" {
+		return lint.Lint(state, m.path.selected)
+	}
+
+	if !m.hasSource {
+		return nil
+	}
+
+	return lint.LintMode(state.ResolveMode(m.mode.selected, m.sourceMode), m.path.selected == "Directory")
+}
+
+// toggleRecursive switches between a single state and separate file and
+// directory states, the directory state starts out as a copy of the file state
+func toggleRecursive(m *Model) {
+	if m.dirState != nil {
+		m.dirState = nil
+		m.recursiveCommand = ""
+		m.recursiveErr = nil
+	} else {
+		m.dirState = m.state.Clone()
+	}
+
+	m.path.recursive = m.dirState != nil
+	m.syncBlocks(m.activeState())
+}
+
+// updateModeInput passes the key to the mode field and applies the mode typed
+// so far to the permissions blocks, relative changes in Relative mode and an
+// octal or symbolic mode otherwise
+func updateModeInput(m *Model, msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	m.input.field, cmd = m.input.field.Update(msg)
+
+	value := strings.TrimSpace(m.input.field.Value())
+	if value == "" {
+		m.input.err = nil
+		return cmd
+	}
+
+	relative := m.mode.selected == "Relative"
+
+	var (
+		parsed *generate.State
+		err    error
+	)
+
+	if relative {
+		parsed, err = generate.ParseRelative(value)
+	} else {
+		parsed, err = generate.ParseMode(value)
+	}
+
+	if err != nil {
+		m.input.err = err
+		return cmd
+	}
+
+	m.input.err = nil
+
+	snapshot := takeSnapshot(m)
+	state := m.activeState()
+	before := m.modeValue(state)
+
+	if relative {
+		state.Changes = parsed.Changes
+		state.SpecialChanges = parsed.SpecialChanges
+	} else {
+		state.Users = parsed.Users
+		state.Special = parsed.Special
+	}
+
+	if m.modeValue(state) == before {
+		return cmd
+	}
+
+	recordEdit(m, snapshot)
+	m.syncBlocks(state)
+
+	return tea.Batch(cmd, updateCommand(generate.User(""), generate.Access(""), false))
+}
+
+// modeValue returns the mode of the state in the selected command mode, the
+// symbolic form is used when it has no octal form
+func (m Model) modeValue(state *generate.State) string {
+	value, err := state.BuildCommand(m.mode.selected)
+	if err != nil {
+		value, _ = state.BuildCommand("Symbolic")
+	}
+
+	return value
+}
+
+// syncModeInput sets the mode field from the state being edited, unless the
+// mode is being typed in it
+func (m Model) syncModeInput() {
+	if m.section == ModeInputSection {
+		return
+	}
+
+	m.input.field.SetValue(m.modeValue(m.activeState()))
+	m.input.err = nil
+}
+
+// takeSnapshot returns a copy of the permissions being edited
+func takeSnapshot(m *Model) Snapshot {
+	snapshot := Snapshot{
+		state:       m.state.Clone(),
+		pathType:    m.path.selected,
+		commandMode: m.mode.selected,
+	}
+
+	if m.dirState != nil {
+		snapshot.dirState = m.dirState.Clone()
+	}
+
+	return snapshot
+}
+
+// recordEdit pushes the snapshot taken before an edit onto the undo stack, a
+// new edit clears the redo stack
+func recordEdit(m *Model, snapshot Snapshot) {
+	m.undo = append(m.undo, snapshot)
+
+	if len(m.undo) > UndoLimit {
+		m.undo = m.undo[1:]
+	}
+
+	m.redo = nil
+}
+
+// undoEdit restores the snapshot on top of the undo stack, it reports false
+// when there is nothing to undo
+func undoEdit(m *Model) bool {
+	if len(m.undo) == 0 {
+		return false
+	}
+
+	m.redo = append(m.redo, takeSnapshot(m))
+	restoreSnapshot(m, m.undo[len(m.undo)-1])
+	m.undo = m.undo[:len(m.undo)-1]
+
+	return true
+}
+
+// redoEdit restores the snapshot on top of the redo stack, it reports false
+// when there is nothing to redo
+func redoEdit(m *Model) bool {
+	if len(m.redo) == 0 {
+		return false
+	}
+
+	m.undo = append(m.undo, takeSnapshot(m))
+	restoreSnapshot(m, m.redo[len(m.redo)-1])
+	m.redo = m.redo[:len(m.redo)-1]
+
+	return true
+}
+
+// restoreSnapshot replaces the permissions being edited with a copy of the
+// snapshot, the command is rebuilt afterwards
+func restoreSnapshot(m *Model, snapshot Snapshot) {
+	state := snapshot.state.Clone()
+	state.Command = m.state.Command
+	state.PWD = m.state.PWD
+
+	m.state = state
+	m.dirState = nil
+
+	if snapshot.dirState != nil {
+		m.dirState = snapshot.dirState.Clone()
+	} else {
+		m.recursiveCommand = ""
+		m.recursiveErr = nil
+	}
+
+	m.path.recursive = m.dirState != nil
+	m.path.selected = snapshot.pathType
+	m.mode.selected = snapshot.commandMode
+	m.permissions.relative = m.mode.selected == "Relative"
+
+	m.syncBlocks(m.activeState())
+}
+
+// buildCommand sets the command from the state, or to a find pair in
+// recursive mode
+func buildCommand(m *Model) error {
+	if m.dirState != nil {
+		return buildRecursiveCommand(m)
+	}
+
+	mode, err := m.state.ModeArgument(m.mode.selected)
+	if err != nil {
+		return err
+	}
+
+	// a relative mode without changes would be a bare chmod
+	if mode == "" {
+		m.state.Command = ""
+		return nil
+	}
+
+	command := strings.Builder{}
+
+	command.WriteString("chmod ")
+
+	if flag := getOptionFlag(m); flag != "" {
+		command.WriteString(fmt.Sprintf("%s ", flag))
+	}
+
+	command.WriteString(mode)
+
+	if len(m.files.selected) > 0 {
+		command.WriteString(fmt.Sprintf(" %s", quoteTargets(m.files.selected)))
+	}
+
+	m.state.Command = command.String()
+
+	return nil
+}
+
+// buildRecursiveCommand sets the command to a find pair applying the file and
+// directory states separately, along with the equivalent chmod -R variant
+func buildRecursiveCommand(m *Model) error {
+	fileMode, err := m.state.ModeArgument(m.mode.selected)
+	if err != nil {
+		return err
+	}
+
+	dirMode, err := m.dirState.ModeArgument(m.mode.selected)
+	if err != nil {
+		return err
+	}
+
+	flag := getOptionFlag(m)
+	target := "."
+
+	if len(m.files.selected) > 0 {
+		target = quoteTargets(m.files.selected)
+	}
+
+	m.state.Command = strings.Join(generate.FindCommands(target, flag, dirMode, fileMode), "\n")
+
+	if m.mode.selected == "Relative" {
+		m.recursiveCommand = ""
+		m.recursiveErr = errors.New("only available for absolute modes")
+
+		return nil
+	}
+
+	expr, err := generate.RecursiveExpression(m.state, m.dirState)
+	if err != nil {
+		m.recursiveCommand = ""
+		m.recursiveErr = err
+
+		return nil
+	}
+
+	m.recursiveCommand = strings.Join(strings.Fields(fmt.Sprintf("chmod -R %s %s %s", flag, expr, target)), " ")
+	m.recursiveErr = nil
+
+	return nil
+}
+
+// quoteTargets quotes the target paths and joins them with spaces
+func quoteTargets(targets []string) string {
+	quoted := make([]string, len(targets))
+
+	for i, v := range targets {
+		quoted[i] = common.QuotePath(v)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func switchSection(m *Model, msg string) {
+
+	switch msg {
+	case "tab", " ":
+		m.setSectionCursor(false)
+		if m.cursor >= NumSections-1 {
+			m.cursor = -1
+		}
+		m.cursor++
+		m.section = getSection(m.cursor)
+		m.setSectionCursor(true)
+
+	case "shift+tab":
+		if m.cursor <= 0 {
+			break
+		}
+
+		m.setSectionCursor(false)
+		m.cursor--
+		m.section = getSection(m.cursor)
+		m.setSectionCursor(true)
+	}
+}
+
+func updateCommand(user generate.User, access generate.Access, active bool) tea.Cmd {
+	return func() tea.Msg {
+		return UpdateCommandMsg{
+			User:   user,
+			Access: access,
+			Active: active,
+		}
+	}
+}
+
+func updateSpecial(special generate.Special, active bool) tea.Cmd {
+	return func() tea.Msg {
+		return UpdateCommandMsg{
+			Special: special,
+			Active:  active,
+		}
+	}
+}
+
+func updateRelative(user generate.User, access generate.Access, special generate.Special, op generate.Operation) tea.Cmd {
+	return func() tea.Msg {
+		return UpdateCommandMsg{
+			User:      user,
+			Access:    access,
+			Special:   special,
+			Relative:  true,
+			Operation: op,
+		}
+	}
+}
+
+// showNotice shows a notice such as "copied!" in place of the command until
+// it's reset
+func showNotice(m *Model, notice string) tea.Cmd {
+	command := m.command()
+
+	m.state.Command = notice
+	m.restore = command
+
+	return resetCommand(command)
+}
+
+// recordHistory adds the command to the top of the history list and appends
+// it to the history file
+func recordHistory(m *Model, action string) tea.Cmd {
+	return recordEntry(m, history.NewEntry(action, m.command(), append([]string{}, m.files.selected...)))
+}
+
+// recordEntry adds the entry to the top of the history list and appends it to
+// the history file
+func recordEntry(m *Model, entry history.Entry) tea.Cmd {
+	m.history.entries = append([]history.Entry{entry}, m.history.entries...)
+
+	if m.history.cursor > 0 {
+		m.history.cursor++
+	}
+
+	path := m.historyPath
+
+	return func() tea.Msg {
+		if path == "" {
+			return nil
+		}
+
+		if err := history.Append(path, entry); err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		return nil
+	}
+}
+
+func loadHistory(path string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := history.Load(path)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		return HistoryMsg(entries)
+	}
+}
+
+func copyCommand() tea.Cmd {
+	return func() tea.Msg {
+		return CopyCommandMsg{}
+	}
+}
+
+func resetCommand(cmd string) tea.Cmd {
+	return tea.Tick(ResetCommandDuration, func(t time.Time) tea.Msg {
+		return ResetCommandMsg(cmd)
+	})
+}
+
+func applyMode(targets []string, state *generate.State, mode, command string) tea.Cmd {
+	return func() tea.Msg {
+		results := make([]ApplyResult, len(targets))
+
+		for i, path := range targets {
+			before, _ := generate.GetPathMode(path)
+			before &= fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+			fileMode, err := state.ApplyMode(path, mode)
+
+			results[i] = ApplyResult{
+				Path:    path,
+				Mode:    fileMode,
+				Changed: err == nil && before != fileMode,
+				Err:     err,
+			}
+		}
+
+		return ApplyResultMsg{Command: command, Results: results}
+	}
+}
+
+func getPWDPermission() tea.Msg {
+	mode, err := generate.GetPWDMode()
+	if err != nil {
+		return ErrorMsg{Err: err}
+	}
+
+	return PWDPermissionMsg(mode.String())
+}
+
+func getSourceMode(path string) tea.Cmd {
+	return func() tea.Msg {
+		var (
+			mode fs.FileMode
+			err  error
+		)
+
+		if path == "" {
+			mode, err = generate.GetPWDMode()
+		} else {
+			mode, err = generate.GetPathMode(path)
+		}
+
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		return SourceModeMsg{
+			Path: path,
+			Mode: mode,
+		}
+	}
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc", "q":
-			return m, tea.Quit
+// readDir lists the entries of dir for the file picker
+func readDir(dir string) tea.Cmd {
+	return func() tea.Msg {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
 
-		case "up", "down", "left", "right", "enter":
-			if m.section == OptionsSection {
-				return m, m.options.updateOptions(msg.String())
-			}
+		files := make([]FileEntry, len(entries))
 
-			if m.section == CommandModeSection {
-				return m, m.mode.updateCommandMode(msg.String())
-			}
+		for i, v := range entries {
+			isDir := v.IsDir()
 
-			if m.section == PathTypeSection {
-				return m, m.path.updatePathType(msg.String())
+			// symlinks to directories can be opened like directories
+			if v.Type()&fs.ModeSymlink != 0 {
+				if stat, err := os.Stat(filepath.Join(dir, v.Name())); err == nil {
+					isDir = stat.IsDir()
+				}
 			}
 
-			if m.section == PermissionsSection {
-				return m, m.permissions.updatePermissions(msg.String())
-			}
+			files[i] = FileEntry{Name: v.Name(), IsDir: isDir}
+		}
 
-		case "tab", " ", "shift+tab":
-			switchSection(&m, msg.String())
+		return FilesMsg{
+			Dir:     dir,
+			Entries: files,
+		}
+	}
+}
 
-		case "?":
-			m.help.ShowAll = !m.help.ShowAll
+// getPreview lists the targets, or the entries of the working directory when
+// there are none. Hidden entries are skipped like ls does
+func getPreview(targets []string) tea.Cmd {
+	return func() tea.Msg {
+		paths := targets
 
-		case "ctrl+c":
-			if !strings.EqualFold(m.state.Command, "") {
-				return m, copyCommand()
+		if len(paths) == 0 {
+			entries, err := os.ReadDir(".")
+			if err != nil {
+				return ErrorMsg{Err: err}
 			}
-		}
 
-	case PWDPermissionMsg:
-		m.state.PWD = string(msg)
+			paths = []string{}
 
-	case UpdateCommandMsg:
-		if !strings.EqualFold(string(msg.User), "") {
-			m.state.Users[msg.User][msg.Access] = msg.Active
+			for _, v := range entries {
+				if !strings.HasPrefix(v.Name(), ".") {
+					paths = append(paths, v.Name())
+				}
+			}
 		}
 
-		command := strings.Builder{}
+		preview := []PreviewEntry{}
 
-		command.WriteString("chmod ")
+		for i := 0; i < len(paths) && i < PreviewLimit; i++ {
+			// chmod follows symlinks, so the mode of the link target is shown
+			stat, err := os.Stat(paths[i])
 
-		command.WriteString(fmt.Sprintf("%s ", getOptionFlag(&m)))
+			entry := PreviewEntry{Path: paths[i], Err: err}
 
-		if m.mode.selected == "Octal" {
-			command.WriteString(m.state.BuildCommand(m.mode.selected))
-		} else if m.path.selected == "Directory" {
-			command.WriteString(fmt.Sprintf("d%s", m.state.BuildCommand(m.mode.selected)))
-		} else {
-			command.WriteString(fmt.Sprintf("-%s", m.state.BuildCommand(m.mode.selected)))
+			if err == nil {
+				entry.Mode = stat.Mode()
+			}
+
+			preview = append(preview, entry)
 		}
 
-		m.state.Command = command.String()
+		return PreviewMsg{
+			Entries: preview,
+			Total:   len(paths),
+		}
+	}
+}
 
-	case CopyCommandMsg:
-		if err := common.CopyToClipboard(m.state.Command); err != nil {
-			command := m.state.Command
-			m.state.Command = "error copying to clipboard"
+// prefill sets the permissions blocks and path type from an existing mode,
+// the path type is kept when it was configured and the mode is the PWD's
+func (m Model) prefill(mode fs.FileMode) {
+	state := generate.NewStateFromMode(mode)
 
-			return m, resetCommand(command)
-		}
+	m.state.Users = state.Users
+	m.state.Special = state.Special
 
-		command := m.state.Command
-		m.state.Command = "copied!"
+	switch {
+	case m.fixedPathType:
 
-		return m, resetCommand(command)
+	case mode.IsDir():
+		m.path.selected = "Directory"
 
-	case ResetCommandMsg:
-		m.state.Command = string(msg)
+	default:
+		m.path.selected = "File"
 	}
 
-	return m, nil
+	m.syncBlocks(m.state)
 }
 
-func switchSection(m *Model, msg string) {
+// applyPreset sets the path type and the permissions blocks from a preset.
+// Presets are absolute modes, so relative mode falls back to octal
+func (m Model) applyPreset(preset generate.Preset) error {
+	state, err := preset.State()
+	if err != nil {
+		return err
+	}
 
-	switch msg {
-	case "tab", " ":
-		m.setSectionCursor(false)
-		if m.cursor >= NumSections {
-			m.cursor = -1
-		}
-		m.cursor++
-		m.section = getSection(m.cursor)
-		m.setSectionCursor(true)
+	if m.mode.selected == "Relative" {
+		m.mode.selected = "Octal"
+		m.permissions.relative = false
+	}
 
-	case "shift+tab":
-		if m.cursor <= 0 {
-			break
-		}
+	m.path.selected = preset.PathType
 
-		m.setSectionCursor(false)
-		m.cursor--
-		m.section = getSection(m.cursor)
-		m.setSectionCursor(true)
-	}
+	// in recursive mode the path type picks the file or directory state
+	active := m.activeState()
+	active.Users = state.Users
+	active.Special = state.Special
+
+	m.syncBlocks(active)
+
+	return nil
 }
 
-func updateCommand(user generate.User, access generate.Access, active bool) tea.Cmd {
-	return func() tea.Msg {
-		return UpdateCommandMsg{
-			User:   user,
-			Access: access,
-			Active: active,
+// syncBlocks sets the selections of the permissions blocks from a state
+func (m Model) syncBlocks(state *generate.State) {
+	for i := 0; i < 3; i++ {
+		user := generate.User(getBlockName(i))
+		selected := []string{}
+		changes := map[string]generate.Operation{}
+
+		for _, v := range m.permissions.values {
+			access := generate.Access(getAccessSymbol(v))
+
+			if state.Users[user][access] {
+				selected = append(selected, v)
+			}
+
+			changes[v] = state.Changes[user][access]
 		}
-	}
-}
 
-func copyCommand() tea.Cmd {
-	return func() tea.Msg {
-		return CopyCommandMsg{}
+		m.permissions.blocks[i].selected = selected
+		m.permissions.blocks[i].changes = changes
 	}
-}
 
-func resetCommand(cmd string) tea.Cmd {
-	return tea.Tick(ResetCommandDuration, func(t time.Time) tea.Msg {
-		return ResetCommandMsg(cmd)
-	})
-}
+	selected := []string{}
+	changes := map[string]generate.Operation{}
 
-func getPWDPermission() tea.Msg {
-	mode, err := generate.GetPWDMode()
-	if err != nil {
-		panic(err)
+	for _, v := range m.permissions.special {
+		special := generate.Special(getSpecialName(v))
+
+		if state.Special[special] {
+			selected = append(selected, v)
+		}
+
+		changes[v] = state.SpecialChanges[special]
 	}
 
-	return PWDPermissionMsg(mode.String())
+	m.permissions.blocks[3].selected = selected
+	m.permissions.blocks[3].changes = changes
 }
 
 func (m Model) View() string {
+	if m.results != nil {
+		return m.renderResults()
+	}
+
 	s := strings.Builder{}
 
 	header := m.renderHeader()
@@ -293,16 +1389,45 @@ func (m Model) View() string {
 
 	s.WriteString(header)
 	s.WriteString("\n")
-	s.WriteString(m.options.renderOptions())
+	s.WriteString(lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		m.options.renderOptions(),
+		"    ",
+		m.presets.renderPresets(m.activeState().FileMode(), m.path.selected),
+	))
 	s.WriteString("\n")
 	s.WriteString(m.mode.renderCommandMode())
 	s.WriteString("\n\n")
 	s.WriteString(m.path.renderPathType())
 	s.WriteString("\n\n")
+	s.WriteString(m.input.renderModeInput())
+	s.WriteString("\n\n")
 	s.WriteString(lists)
 	s.WriteString("\n")
-	s.WriteString(footer)
+	s.WriteString(m.files.renderFiles())
+	s.WriteString("\n\n")
+	s.WriteString(m.history.renderHistory())
 	s.WriteString("\n\n")
+
+	if m.hasSource {
+		s.WriteString(m.renderDiff())
+		s.WriteString("\n\n")
+	}
+
+	if m.preview != nil {
+		s.WriteString(m.renderPreview())
+		s.WriteString("\n\n")
+	}
+
+	s.WriteString(footer)
+	s.WriteString("\n")
+
+	if findings := m.lintFindings(); len(findings) > 0 {
+		s.WriteString(m.renderLint(findings))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
 	s.WriteString(help)
 
 	return s.String()
@@ -330,6 +1455,147 @@ func (o *Options) updateOptions(key string) tea.Cmd {
 	return nil
 }
 
+func (p *Presets) updatePresets(key string) tea.Cmd {
+	switch key {
+	case "up":
+		if p.cursor <= 0 {
+			break
+		}
+		p.cursor--
+
+	case "down":
+		if p.cursor >= len(p.values)-1 {
+			break
+		}
+		p.cursor++
+
+	case "enter":
+		if p.cursor < 0 || p.cursor >= len(p.values) {
+			break
+		}
+
+		preset := p.values[p.cursor]
+
+		return func() tea.Msg {
+			return PresetMsg(preset)
+		}
+	}
+
+	return nil
+}
+
+// updateFiles moves through the entries of the file picker, right opens the
+// directory under the cursor, left opens the parent and enter toggles the
+// selection of the entry
+func (f *Files) updateFiles(key string) tea.Cmd {
+	switch key {
+	case "up":
+		if f.cursor <= 0 {
+			break
+		}
+		f.cursor--
+
+		if f.cursor < f.offset {
+			f.offset = f.cursor
+		}
+
+	case "down":
+		if f.cursor >= len(f.entries)-1 {
+			break
+		}
+		f.cursor++
+
+		if f.cursor >= f.offset+FilesLimit {
+			f.offset = f.cursor - FilesLimit + 1
+		}
+
+	case "right":
+		if f.cursor < 0 || f.cursor >= len(f.entries) || !f.entries[f.cursor].IsDir {
+			break
+		}
+
+		return readDir(filepath.Join(f.dir, f.entries[f.cursor].Name))
+
+	case "left":
+		return readDir(filepath.Join(f.dir, ".."))
+
+	case "enter":
+		if f.cursor < 0 || f.cursor >= len(f.entries) {
+			break
+		}
+
+		f.toggle(filepath.Join(f.dir, f.entries[f.cursor].Name))
+
+		return tea.Batch(
+			updateCommand(generate.User(""), generate.Access(""), false),
+			getPreview(append([]string{}, f.selected...)),
+		)
+	}
+
+	return nil
+}
+
+// toggle adds path to the selected targets or removes it when it's already
+// selected, targets are compared by their clean form
+func (f *Files) toggle(path string) {
+	if i := f.findSelected(path); i >= 0 {
+		f.selected = append(f.selected[:i], f.selected[i+1:]...)
+		return
+	}
+
+	f.selected = append(f.selected, path)
+}
+
+// findSelected returns the index of path in the selected targets
+func (f *Files) findSelected(path string) int {
+	for i, v := range f.selected {
+		if filepath.Clean(v) == filepath.Clean(path) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// updateHistory moves through the history entries, enter copies the entry
+// under the cursor again
+func (h *History) updateHistory(key string) tea.Cmd {
+	switch key {
+	case "up":
+		if h.cursor <= 0 {
+			break
+		}
+		h.cursor--
+
+		if h.cursor < h.offset {
+			h.offset = h.cursor
+		}
+
+	case "down":
+		if h.cursor >= len(h.entries)-1 {
+			break
+		}
+		h.cursor++
+
+		if h.cursor >= h.offset+HistoryLimit {
+			h.offset = h.cursor - HistoryLimit + 1
+		}
+
+	case "enter":
+		if h.cursor < 0 || h.cursor >= len(h.entries) {
+			break
+		}
+
+		entry := h.entries[h.cursor]
+
+		return func() tea.Msg {
+			return CopyHistoryMsg(entry)
+		}
+	}
+
+	return nil
+}
+
 func (c *CommandMode) updateCommandMode(key string) tea.Cmd {
 	switch key {
 	case "left":
@@ -388,14 +1654,14 @@ func (p *Permissions) updatePermissions(key string) tea.Cmd {
 		p.blocks[p.cursor].cursor--
 
 	case "down":
-		if p.blocks[p.cursor].cursor >= len(p.values)-1 {
+		if p.blocks[p.cursor].cursor >= len(p.blockValues(p.cursor))-1 {
 			break
 		}
 
 		p.blocks[p.cursor].cursor++
 
 	case "right":
-		if p.cursor >= len(p.values)-1 {
+		if p.cursor >= len(p.blocks)-1 {
 			break
 		}
 
@@ -409,11 +1675,15 @@ func (p *Permissions) updatePermissions(key string) tea.Cmd {
 		p.cursor--
 
 	case "enter":
-		item := p.values[p.blocks[p.cursor].cursor]
+		item := p.blockValues(p.cursor)[p.blocks[p.cursor].cursor]
 		selected := p.blocks[p.cursor].selected
 		user := getBlockName(p.cursor)
 		access := getAccessSymbol(item)
 
+		if p.relative {
+			return p.cycleChange(item)
+		}
+
 		// remove item if already exists [selected]
 		if common.IncludesString(selected, item) {
 			index := common.FindIndexString(selected, item)
@@ -426,17 +1696,66 @@ func (p *Permissions) updatePermissions(key string) tea.Cmd {
 
 			p.blocks[p.cursor].selected = selected
 
+			if user == "special" {
+				return updateSpecial(generate.Special(getSpecialName(item)), false)
+			}
+
 			return updateCommand(generate.User(user), generate.Access(access), false)
 		}
 
 		p.blocks[p.cursor].selected = append(selected, item)
 
+		if user == "special" {
+			return updateSpecial(generate.Special(getSpecialName(item)), true)
+		}
+
 		return updateCommand(generate.User(user), generate.Access(access), true)
 	}
 
 	return nil
 }
 
+// cycleChange moves an item in the focused block through unchanged -> add -> remove
+func (p *Permissions) cycleChange(item string) tea.Cmd {
+	block := &p.blocks[p.cursor]
+
+	if block.changes == nil {
+		block.changes = map[string]generate.Operation{}
+	}
+
+	var op generate.Operation
+
+	switch block.changes[item] {
+	case generate.Unchanged:
+		op = generate.Add
+
+	case generate.Add:
+		op = generate.Remove
+
+	case generate.Remove:
+		op = generate.Unchanged
+	}
+
+	block.changes[item] = op
+
+	user := getBlockName(p.cursor)
+
+	if user == "special" {
+		return updateRelative(generate.User(""), generate.Access(""), generate.Special(getSpecialName(item)), op)
+	}
+
+	return updateRelative(generate.User(user), generate.Access(getAccessSymbol(item)), generate.Special(""), op)
+}
+
+// blockValues returns the selectable values for the block at the given index
+func (p *Permissions) blockValues(blockIndex int) []string {
+	if blockIndex == 3 {
+		return p.special
+	}
+
+	return p.values
+}
+
 func getBlockName(blockIndex int) string {
 	switch blockIndex {
 	case 0:
@@ -447,6 +1766,24 @@ func getBlockName(blockIndex int) string {
 
 	case 2:
 		return "other"
+
+	case 3:
+		return "special"
+	}
+
+	return ""
+}
+
+func getSpecialName(special string) string {
+	switch special {
+	case "SetUID":
+		return "setuid"
+
+	case "SetGID":
+		return "setgid"
+
+	case "Sticky":
+		return "sticky"
 	}
 
 	return ""
@@ -462,6 +1799,9 @@ func getAccessSymbol(access string) string {
 
 	case "Execute":
 		return "x"
+
+	case "Execute (dirs only)":
+		return "X"
 	}
 
 	return ""
@@ -479,7 +1819,19 @@ func getSection(cursor int) Section {
 		return PathTypeSection
 
 	case 3:
+		return ModeInputSection
+
+	case 4:
 		return PermissionsSection
+
+	case 5:
+		return PresetsSection
+
+	case 6:
+		return FilesSection
+
+	case 7:
+		return HistorySection
 	}
 
 	return 0
@@ -526,6 +1878,18 @@ func (m Model) setSectionCursor(active bool) {
 		}
 		m.path.cursor = -1
 
+	case ModeInputSection:
+		if active {
+			m.input.field.Focus()
+			m.input.field.CursorEnd()
+			break
+		}
+		m.input.field.Blur()
+
+		// an invalid mode left in the field is replaced with the current one
+		m.input.field.SetValue(m.modeValue(m.activeState()))
+		m.input.err = nil
+
 	case PermissionsSection:
 		if active {
 			m.permissions.cursor = 0
@@ -533,5 +1897,28 @@ func (m Model) setSectionCursor(active bool) {
 			break
 		}
 		m.permissions.cursor = -1
+
+	case PresetsSection:
+		if active {
+			m.presets.cursor = 0
+			break
+		}
+		m.presets.cursor = -1
+
+	case FilesSection:
+		if active {
+			m.files.cursor = 0
+			m.files.offset = 0
+			break
+		}
+		m.files.cursor = -1
+
+	case HistorySection:
+		if active {
+			m.history.cursor = 0
+			m.history.offset = 0
+			break
+		}
+		m.history.cursor = -1
 	}
 }
@@ -1,8 +1,17 @@
 package ui
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/Mayowa-Ojo/chmod-cli/internal/common"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/generate"
+	"github.com/Mayowa-Ojo/chmod-cli/internal/history"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/require"
 )
@@ -13,7 +22,7 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("test update options", func(t *testing.T) {
 		t.Skip()
-		model := createModel()
+		model := createModel(Settings{})
 		msg = tea.KeyMsg{
 			Type:  tea.KeyDown,
 			Runes: nil,
@@ -59,7 +68,7 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("test update command-mode", func(t *testing.T) {
 		t.Skip()
-		model := createModel()
+		model := createModel(Settings{})
 		msg = tea.KeyMsg{
 			Type:  tea.KeyTab,
 			Runes: nil,
@@ -109,7 +118,7 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("test update path-type", func(t *testing.T) {
 		// t.Skip()
-		model := createModel()
+		model := createModel(Settings{})
 		msg := tea.KeyMsg{
 			Type:  tea.KeyTab,
 			Runes: nil,
@@ -143,4 +152,582 @@ func TestUpdate(t *testing.T) {
 			t.Errorf("Expected cursor to be '1', instead got '%d'", cursor)
 		}
 	})
+	t.Run("test error message", func(t *testing.T) {
+		model := createModel(Settings{})
+
+		model, cmd := model.Update(ErrorMsg{Err: errors.New("stat failed")})
+		is.Nil(cmd)
+
+		if err := model.(Model).err; err == nil || err.Error() != "stat failed" {
+			t.Errorf("Expected err to be 'stat failed', instead got '%v'", err)
+		}
+
+		model, _ = model.Update(UpdateCommandMsg{})
+
+		if err := model.(Model).err; err != nil {
+			t.Errorf("Expected err to be cleared, instead got '%v'", err)
+		}
+	})
+	t.Run("test update relative permissions", func(t *testing.T) {
+		m := createModel(Settings{}).(Model)
+		m.mode.selected = "Relative"
+		m.permissions.relative = true
+		m.permissions.cursor = 1
+		m.permissions.blocks[1].cursor = 1
+
+		cmd := m.permissions.updatePermissions("enter")
+		is.NotNil(cmd)
+
+		model, _ := m.Update(cmd())
+
+		if command := model.(Model).state.Command; command != "chmod g+w" {
+			t.Errorf("Expected command to be 'chmod g+w', instead got '%s'", command)
+		}
+
+		m.permissions.updatePermissions("enter")
+
+		if op := m.permissions.blocks[1].changes["Write"]; op != generate.Remove {
+			t.Errorf("Expected operation to be '-', instead got '%s'", op)
+		}
+	})
+	t.Run("test relative mode without changes", func(t *testing.T) {
+		m := createModel(Settings{Print: true, CommandMode: "Relative", Targets: []string{"app.conf"}}).(Model)
+
+		model, _ := m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+		is.NoError(m.err)
+		is.Empty(m.state.Command)
+		is.Contains(m.View(), "no changes yet")
+
+		// nothing to copy or apply
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+		is.Nil(cmd)
+
+		model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		is.Nil(cmd)
+		is.Error(model.(Model).err)
+
+		// nor to print
+		quit, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+		_, err := quit.(Model).printCommand()
+		is.Error(err)
+
+		// recursive mode has no find commands either
+		m.dirState = m.state.Clone()
+		is.NoError(buildCommand(&m))
+		is.Empty(m.state.Command)
+	})
+	t.Run("test apply without targets", func(t *testing.T) {
+		model := createModel(Settings{})
+
+		model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		is.Nil(cmd)
+
+		if err := model.(Model).err; err == nil {
+			t.Errorf("Expected err to be set when applying without targets")
+		}
+	})
+
+	t.Run("test apply results", func(t *testing.T) {
+		model := createModel(Settings{Targets: []string{"missing"}})
+
+		model, cmd := model.Update(ApplyResultMsg{Command: "chmod 644 missing", Results: []ApplyResult{{Path: "missing", Err: errors.New("not found")}}})
+		is.Len(model.(Model).results, 1)
+
+		// nothing changed so nothing is recorded
+		is.Nil(cmd)
+		is.Empty(model.(Model).history.entries)
+
+		_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		is.NotNil(cmd)
+	})
+	t.Run("test prefill from source mode", func(t *testing.T) {
+		model := createModel(Settings{})
+
+		model, cmd := model.Update(SourceModeMsg{Mode: fs.ModeDir | fs.ModeSetgid | 0750})
+		is.NotNil(cmd)
+
+		model, _ = model.Update(cmd())
+		m := model.(Model)
+
+		is.Equal("Directory", m.path.selected)
+		is.ElementsMatch([]string{"Read", "Execute"}, m.permissions.blocks[1].selected)
+		is.ElementsMatch([]string{"SetGID"}, m.permissions.blocks[3].selected)
+
+		if command := m.state.Command; command != "chmod u=rwx,g=rxs,o=" {
+			t.Errorf("Expected command to be 'chmod u=rwx,g=rxs,o=', instead got '%s'", command)
+		}
+
+		view := regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(m.View(), "")
+		is.Contains(view, "Mode: drwxr-s---")
+	})
+	t.Run("test diff view", func(t *testing.T) {
+		model := createModel(Settings{Source: "app.sh"})
+
+		model, cmd := model.Update(SourceModeMsg{Path: "app.sh", Mode: 0755})
+		model, _ = model.Update(cmd())
+
+		m := model.(Model)
+		m.state.Users[generate.Group][generate.WriteAccess] = true
+		m.state.Users[generate.Other][generate.ExecuteAccess] = false
+
+		view := regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(m.View(), "")
+
+		is.Contains(view, "app.sh")
+		is.Contains(view, "Before: rwxr-xr-x (755)")
+		is.Contains(view, "After:  rwxrwxr-- (774)")
+	})
+	t.Run("test preview", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "app.conf")
+		sub := filepath.Join(dir, "static")
+
+		is.NoError(os.WriteFile(file, []byte{}, 0644))
+		is.NoError(os.Chmod(file, 0644))
+		is.NoError(os.Mkdir(sub, 0755))
+		is.NoError(os.Chmod(sub, 0755))
+
+		targets := []string{file, sub, filepath.Join(dir, "missing")}
+
+		model, _ := createModel(Settings{Targets: targets}).Update(getPreview(targets)())
+
+		m := model.(Model)
+		is.Len(m.preview, 3)
+		is.Equal(3, m.previewTotal)
+		is.Error(m.preview[2].Err)
+
+		for _, v := range []generate.Access{generate.ReadAccess, generate.WriteAccess, generate.ConditionalExecuteAccess} {
+			m.state.Users[generate.Owner][v] = true
+		}
+
+		for _, v := range []generate.Access{generate.ReadAccess, generate.ConditionalExecuteAccess} {
+			m.state.Users[generate.Group][v] = true
+		}
+
+		view := regexp.MustCompile(`\x1b\[[0-9;]*m`).ReplaceAllString(m.View(), "")
+
+		is.Contains(view, "-rw-r--r-- → -rw-r-----  "+file)
+		is.Contains(view, "drwxr-xr-x → drwxr-x---  "+sub)
+		is.Contains(view, "missing")
+	})
+	t.Run("test file picker", func(t *testing.T) {
+		dir := t.TempDir()
+
+		is.NoError(os.WriteFile(filepath.Join(dir, "my file.txt"), []byte{}, 0644))
+		is.NoError(os.Mkdir(filepath.Join(dir, "static"), 0755))
+
+		m := createModel(Settings{CommandMode: "Octal", Preset: "Regular file"}).(Model)
+
+		model, _ := m.Update(readDir(dir)())
+		m = model.(Model)
+		is.Equal(dir, m.files.dir)
+		is.Equal([]FileEntry{{Name: "my file.txt"}, {Name: "static", IsDir: true}}, m.files.entries)
+
+		m.section = FilesSection
+		m.setSectionCursor(true)
+
+		model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		is.NotNil(cmd)
+
+		model, _ = model.Update(UpdateCommandMsg{})
+		m = model.(Model)
+
+		target := filepath.Join(dir, "my file.txt")
+		is.Equal([]string{target}, m.files.selected)
+
+		if command := m.state.Command; command != "chmod 644 "+common.ShellQuote(target) {
+			t.Errorf("Expected command to end with the quoted target, instead got '%s'", command)
+		}
+
+		// symbolic commands with targets are valid chmod arguments
+		m.mode.selected = "Symbolic"
+
+		model, _ = m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+
+		if command := m.state.Command; command != "chmod u=rw,go=r "+common.ShellQuote(target) {
+			t.Errorf("Expected command to be 'chmod u=rw,go=r %s', instead got '%s'", common.ShellQuote(target), command)
+		}
+
+		// targets starting with - or = aren't read as options or expanded
+		selected := m.files.selected
+		m.files.selected = []string{"-rf", "=ls"}
+
+		is.NoError(buildCommand(&m))
+		is.Equal("chmod u=rw,go=r ./-rf ./=ls", m.state.Command)
+
+		m.files.selected = selected
+
+		// selecting the entry again removes it
+		m.files.updateFiles("enter")
+		is.Empty(m.files.selected)
+
+		m.files.updateFiles("down")
+		is.Equal(1, m.files.cursor)
+
+		msg := m.files.updateFiles("right")()
+		is.Equal(filepath.Join(dir, "static"), msg.(FilesMsg).Dir)
+
+		msg = m.files.updateFiles("left")()
+		is.Equal(filepath.Dir(dir), msg.(FilesMsg).Dir)
+
+		is.Contains(m.View(), "static/")
+	})
+	t.Run("test recursive mode", func(t *testing.T) {
+		model := createModel(Settings{})
+
+		model, cmd := model.Update(SourceModeMsg{Mode: 0644})
+		model, _ = model.Update(cmd())
+
+		model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		is.NotNil(cmd)
+
+		m := model.(Model)
+		is.NotNil(m.dirState)
+
+		m.path.selected = "Directory"
+		m.permissions.cursor = 0
+		m.permissions.blocks[0].cursor = 2
+
+		model, _ = m.Update(m.permissions.updatePermissions("enter")())
+		m = model.(Model)
+
+		expected := "find . -type d -exec chmod u=rwx,go=r {} +\nfind . -type f -exec chmod u=rw,go=r {} +"
+		is.Equal(expected, m.state.Command)
+		is.Equal("chmod -R u=rwX,go=r .", m.recursiveCommand)
+
+		model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+		is.Nil(model.(Model).dirState)
+	})
+	t.Run("test undo and redo", func(t *testing.T) {
+		m := createModel(Settings{CommandMode: "Octal", Preset: "Regular file"}).(Model)
+
+		model, _ := m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+		is.Empty(m.undo)
+
+		m.permissions.cursor = 0
+		m.permissions.blocks[0].cursor = 2
+
+		model, _ = m.Update(m.permissions.updatePermissions("enter")())
+		model, _ = model.Update(PresetMsg(generate.Presets[3]))
+		m = model.(Model)
+		is.Len(m.undo, 2)
+		is.Equal("Directory", m.path.selected)
+
+		undo := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")}
+
+		model, cmd := m.Update(undo)
+		is.NotNil(cmd)
+
+		model, _ = model.Update(cmd())
+		m = model.(Model)
+		is.Equal("File", m.path.selected)
+
+		if command := m.state.Command; command != "chmod 744" {
+			t.Errorf("Expected command to be 'chmod 744', instead got '%s'", command)
+		}
+
+		model, cmd = m.Update(undo)
+		model, _ = model.Update(cmd())
+		m = model.(Model)
+		is.Equal([]string{"Read", "Write"}, m.permissions.blocks[0].selected)
+
+		if command := m.state.Command; command != "chmod 644" {
+			t.Errorf("Expected command to be 'chmod 644', instead got '%s'", command)
+		}
+
+		// nothing left to undo
+		_, cmd = m.Update(undo)
+		is.Nil(cmd)
+
+		model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+		is.NotNil(cmd)
+
+		model, _ = model.Update(cmd())
+		m = model.(Model)
+		is.Len(m.redo, 1)
+
+		if command := m.state.Command; command != "chmod 744" {
+			t.Errorf("Expected command to be 'chmod 744', instead got '%s'", command)
+		}
+
+		// a new edit clears the redo stack
+		model, _ = m.Update(UpdateCommandMsg{User: generate.Other, Access: generate.ReadAccess})
+		is.Empty(model.(Model).redo)
+	})
+	t.Run("test history", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+
+		m := createModel(Settings{CommandMode: "Octal", Preset: "Regular file", Targets: []string{"app.conf"}, HistoryPath: path}).(Model)
+
+		model, _ := m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+
+		is.Nil(recordHistory(&m, history.Copy)())
+		is.Len(m.history.entries, 1)
+		is.Equal("chmod 644 app.conf", m.history.entries[0].Command)
+
+		model, _ = m.Update(loadHistory(path)())
+		m = model.(Model)
+		is.Len(m.history.entries, 1)
+		is.Equal(history.Copy, m.history.entries[0].Action)
+		is.Equal([]string{"app.conf"}, m.history.entries[0].Targets)
+
+		m.section = HistorySection
+		m.setSectionCursor(true)
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		is.NotNil(cmd)
+		is.Equal("chmod 644 app.conf", cmd().(CopyHistoryMsg).Command)
+
+		is.Contains(m.View(), "chmod 644 app.conf")
+
+		// apply records the command it ran for the targets it changed
+		dir := t.TempDir()
+		changed := filepath.Join(dir, "changed")
+		unchanged := filepath.Join(dir, "unchanged")
+
+		is.NoError(os.WriteFile(changed, []byte{}, 0600))
+		is.NoError(os.WriteFile(unchanged, []byte{}, 0644))
+
+		m.files.selected = []string{changed, unchanged, filepath.Join(dir, "missing")}
+		m.section = CommandModeSection
+
+		model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		is.NotNil(cmd)
+		is.Len(model.(Model).history.entries, 1)
+
+		model, cmd = model.Update(cmd())
+		m = model.(Model)
+		is.NotNil(cmd)
+		is.Nil(cmd())
+		is.Len(m.results, 3)
+		is.Len(m.history.entries, 2)
+		is.Equal(history.Apply, m.history.entries[0].Action)
+		is.Equal([]string{changed}, m.history.entries[0].Targets)
+
+		if command := m.history.entries[0].Command; !strings.HasPrefix(command, "chmod 644 ") {
+			t.Errorf("Expected command to be 'chmod 644 ...', instead got '%s'", command)
+		}
+
+		// without a history path nothing is written
+		m = createModel(Settings{}).(Model)
+		is.Nil(recordHistory(&m, history.Copy)())
+		is.Len(m.history.entries, 1)
+	})
+	t.Run("test mode input", func(t *testing.T) {
+		m := createModel(Settings{CommandMode: "Octal", Preset: "Regular file"}).(Model)
+
+		model, _ := m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+		is.Equal("644", m.input.field.Value())
+
+		m.cursor = 3
+		m.section = getSection(m.cursor)
+		m.setSectionCursor(true)
+		m.input.field.SetValue("")
+
+		for i, v := range "640" {
+			model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{v}})
+			m = model.(Model)
+
+			// the mode is incomplete until the last digit
+			if i < 2 {
+				is.Error(m.input.err)
+			}
+		}
+
+		is.NoError(m.input.err)
+		is.Equal([]string{"Read"}, m.permissions.blocks[1].selected)
+		is.Empty(m.permissions.blocks[2].selected)
+		is.Len(m.undo, 1)
+
+		model, _ = m.Update(UpdateCommandMsg{})
+		m = model.(Model)
+
+		if command := m.state.Command; command != "chmod 640" {
+			t.Errorf("Expected command to be 'chmod 640', instead got '%s'", command)
+		}
+
+		// q is typed into the field instead of quitting
+		model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		m = model.(Model)
+		is.False(m.done)
+		is.Equal("640q", m.input.field.Value())
+		is.Error(m.input.err)
+		is.Contains(m.View(), "invalid symbolic mode '640q'")
+
+		// leaving the field restores the current mode
+		model, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = model.(Model)
+		is.Equal(PermissionsSection, m.section)
+		is.Equal("640", m.input.field.Value())
+		is.NoError(m.input.err)
+
+		model, _ = m.Update(UpdateCommandMsg{User: generate.Other, Access: generate.ReadAccess, Active: true})
+		is.Equal("644", model.(Model).input.field.Value())
+
+		m = createModel(Settings{CommandMode: "Relative"}).(Model)
+		m.section = ModeInputSection
+		m.setSectionCursor(true)
+
+		for _, v := range "g+w" {
+			model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{v}})
+			m = model.(Model)
+		}
+
+		is.NoError(m.input.err)
+		is.Equal(generate.Add, m.permissions.blocks[1].changes["Write"])
+	})
+	t.Run("test conditional execute in octal mode", func(t *testing.T) {
+		m := createModel(Settings{}).(Model)
+		m.mode.selected = "Octal"
+		m.permissions.cursor = 0
+		m.permissions.blocks[0].cursor = 3
+
+		model, _ := m.Update(m.permissions.updatePermissions("enter")())
+
+		if err := model.(Model).err; err == nil {
+			t.Errorf("Expected err to be set for conditional execute in octal mode")
+		}
+
+		// the stale command isn't applied
+		m = model.(Model)
+		m.files.selected = []string{"app.conf"}
+
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+		is.Nil(cmd)
+
+		m.files.selected = nil
+
+		m = model.(Model)
+		m.mode.selected = "Symbolic"
+
+		model, _ = m.Update(UpdateCommandMsg{})
+
+		if command := model.(Model).state.Command; command != "chmod u=X,go=" {
+			t.Errorf("Expected command to be 'chmod u=X,go=', instead got '%s'", command)
+		}
+	})
+	t.Run("test lint findings", func(t *testing.T) {
+		m := createModel(Settings{}).(Model)
+		is.Empty(m.lintFindings())
+
+		m.state.Users[generate.Other][generate.WriteAccess] = true
+
+		findings := m.lintFindings()
+		is.Len(findings, 1)
+		is.Equal("world-writable", findings[0].Rule)
+		is.Contains(m.View(), "world-writable")
+	})
+	t.Run("test presets", func(t *testing.T) {
+		m := createModel(Settings{}).(Model)
+		m.mode.selected = "Relative"
+		m.permissions.relative = true
+		m.section = PresetsSection
+		m.presets.cursor = 5
+
+		model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		is.NotNil(cmd)
+
+		model, cmd = model.Update(cmd())
+		is.NotNil(cmd)
+
+		model, _ = model.Update(cmd())
+		m = model.(Model)
+
+		is.Equal("Directory", m.path.selected)
+		is.False(m.permissions.relative)
+		is.ElementsMatch([]string{"Read", "Write", "Execute"}, m.permissions.blocks[1].selected)
+		is.ElementsMatch([]string{"SetGID"}, m.permissions.blocks[3].selected)
+
+		if command := m.state.Command; command != "chmod 2775" {
+			t.Errorf("Expected command to be 'chmod 2775', instead got '%s'", command)
+		}
+
+		// an empty list has nothing to select
+		m = createModel(Settings{Presets: []generate.Preset{}}).(Model)
+		m.section = PresetsSection
+		m.setSectionCursor(true)
+
+		model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		is.Nil(cmd)
+		is.NotEmpty(model.View())
+	})
+	t.Run("test settings", func(t *testing.T) {
+		settings := Settings{Option: "verbose", CommandMode: "octal", PathType: "directory", Preset: "private directory"}
+		is.NoError(settings.normalize())
+		is.Equal(Settings{Option: "Verbose", CommandMode: "Octal", PathType: "Directory", Preset: "Private directory"}, settings)
+
+		m := createModel(settings).(Model)
+		is.Equal("Verbose", m.options.selected)
+		is.Equal("Octal", m.mode.selected)
+		is.True(m.fromPreset)
+		is.ElementsMatch([]string{"Read", "Write", "Execute"}, m.permissions.blocks[0].selected)
+
+		// the source mode doesn't replace the initial preset
+		model, cmd := m.Update(SourceModeMsg{Mode: fs.ModeDir | 0755})
+		model, _ = model.Update(cmd())
+
+		if command := model.(Model).state.Command; command != "chmod --verbose 700" {
+			t.Errorf("Expected command to be 'chmod --verbose 700', instead got '%s'", command)
+		}
+
+		// the working directory doesn't replace the configured path type
+		model, cmd = createModel(Settings{PathType: "File"}).Update(SourceModeMsg{Mode: fs.ModeDir | 0755})
+		model, _ = model.Update(cmd())
+		is.Equal("File", model.(Model).path.selected)
+		is.ElementsMatch([]string{"Read", "Write", "Execute"}, model.(Model).permissions.blocks[0].selected)
+
+		// an explicit source does
+		model, _ = createModel(Settings{PathType: "File", Source: "static"}).Update(SourceModeMsg{Path: "static", Mode: fs.ModeDir | 0755})
+		is.Equal("Directory", model.(Model).path.selected)
+
+		for _, v := range []Settings{{Option: "loud"}, {CommandMode: "hex"}, {PathType: "socket"}, {Preset: "missing"}} {
+			is.Error(v.normalize())
+		}
+
+		is.Error(SetTheme("missing"))
+	})
+	t.Run("test print mode", func(t *testing.T) {
+		m := createModel(Settings{Print: true, CommandMode: "Octal", Preset: "Regular file"}).(Model)
+		is.True(m.keys.Cancel.Enabled())
+
+		model, _ := m.Update(UpdateCommandMsg{})
+
+		quit, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		is.NotNil(cmd)
+
+		command, err := quit.(Model).printCommand()
+		is.NoError(err)
+		is.Equal("chmod 644", command)
+
+		// a notice shown in place of the command isn't printed
+		m = model.(Model)
+		m.restore = m.state.Command
+		m.state.Command = "copied!"
+
+		quit, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+		command, err = quit.(Model).printCommand()
+		is.NoError(err)
+		is.Equal("chmod 644", command)
+
+		quit, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+		command, err = quit.(Model).printCommand()
+		is.NoError(err)
+		is.Empty(command)
+
+		// quitting before the first update still prints the command
+		quit, _ = createModel(Settings{Print: true, CommandMode: "Octal", Preset: "Regular file"}).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+
+		command, err = quit.(Model).printCommand()
+		is.NoError(err)
+		is.Equal("chmod 644", command)
+
+		is.False(createModel(Settings{}).(Model).keys.Cancel.Enabled())
+	})
 }